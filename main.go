@@ -4,6 +4,7 @@ import (
 	"context"
 	"embed"
 	"flag"
+	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
@@ -26,6 +27,7 @@ import (
 	"github.com/xhilmi/kubedash/pkg/handlers/resources"
 	"github.com/xhilmi/kubedash/pkg/middleware"
 	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/portforward"
 	"github.com/xhilmi/kubedash/pkg/rbac"
 	"github.com/xhilmi/kubedash/pkg/utils"
 	"github.com/xhilmi/kubedash/pkg/version"
@@ -155,6 +157,13 @@ func setupAPIRouter(r *gin.RouterGroup, cm *cluster.ClusterManager) {
 			apiKeyAPI.POST("/", handlers.CreateAPIKey)
 			apiKeyAPI.DELETE("/:id", handlers.DeleteAPIKey)
 		}
+
+		recordingAPI := adminAPI.Group("/terminal-recordings")
+		{
+			recordingAPI.GET("/", handlers.ListTerminalRecordings)
+			recordingAPI.GET("/:id/download", handlers.DownloadTerminalRecording)
+			recordingAPI.GET("/:id/replay", handlers.ReplayTerminalRecording)
+		}
 	}
 
 	// API routes group (protected)
@@ -163,6 +172,7 @@ func setupAPIRouter(r *gin.RouterGroup, cm *cluster.ClusterManager) {
 	api.Use(authHandler.RequireAuth(), middleware.ClusterMiddleware(cm))
 	{
 		api.GET("/overview", handlers.GetOverview)
+		api.GET("/topology/:namespace", resources.GetNamespaceTopology)
 
 		promHandler := handlers.NewPromHandler()
 		api.GET("/prometheus/resource-usage-history", promHandler.GetResourceUsageHistory)
@@ -170,6 +180,7 @@ func setupAPIRouter(r *gin.RouterGroup, cm *cluster.ClusterManager) {
 
 		logsHandler := handlers.NewLogsHandler()
 		api.GET("/logs/:namespace/:podName/ws", logsHandler.HandleLogsWebSocket)
+		api.GET("/logs/:namespace/:podName/download", logsHandler.DownloadLogs)
 
 		terminalHandler := handlers.NewTerminalHandler()
 		api.GET("/terminal/:namespace/:podName/ws", terminalHandler.HandleTerminalWebSocket)
@@ -177,7 +188,14 @@ func setupAPIRouter(r *gin.RouterGroup, cm *cluster.ClusterManager) {
 		nodeTerminalHandler := handlers.NewNodeTerminalHandler()
 		api.GET("/node-terminal/:nodeName/ws", nodeTerminalHandler.HandleNodeTerminalWebSocket)
 
-		searchHandler := handlers.NewSearchHandler()
+		copyHandler := handlers.NewCopyHandler()
+		api.GET("/cp/:namespace/:podName/download", copyHandler.Download)
+		api.POST("/cp/:namespace/:podName/upload", copyHandler.Upload)
+
+		portForwardHandler := handlers.NewPortForwardHandler()
+		api.GET("/portforward/:namespace/:kind/:name/ws", portForwardHandler.HandlePortForwardWebSocket)
+
+		searchHandler := handlers.NewSearchHandler(cm)
 		api.GET("/search", searchHandler.GlobalSearch)
 
 		resourceApplyHandler := handlers.NewResourceApplyHandler()
@@ -194,6 +212,15 @@ func setupAPIRouter(r *gin.RouterGroup, cm *cluster.ClusterManager) {
 }
 
 func main() {
+	// Companion mode, exposes cluster ports locally through a Kite server
+	if len(os.Args) > 1 && os.Args[1] == "port-forward" {
+		if err := portforward.Run(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	klog.InitFlags(nil)
 	flag.Parse()
 	go func() {
@@ -213,6 +240,8 @@ func main() {
 	model.InitDB()
 	rbac.InitRBAC()
 	internal.LoadConfigFromEnv()
+	handlers.StartTerminalRecordingCleanup()
+	cluster.StartEventArchiveCleanup()
 
 	cm, err := cluster.NewClusterManager()
 	if err != nil {
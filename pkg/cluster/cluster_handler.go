@@ -1,9 +1,11 @@
 package cluster
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"sort"
 	"strconv"
 	"time"
@@ -45,14 +47,19 @@ func (cm *ClusterManager) GetClusterList(c *gin.Context) {
 	result := make([]gin.H, 0, len(clusters))
 	for _, cluster := range clusters {
 		clusterInfo := gin.H{
-			"id":            cluster.ID,
-			"name":          cluster.Name,
-			"description":   cluster.Description,
-			"enabled":       cluster.Enable,
-			"inCluster":     cluster.InCluster,
-			"isDefault":     cluster.IsDefault,
-			"prometheusURL": cluster.PrometheusURL,
-			"config":        "",
+			"id":             cluster.ID,
+			"name":           cluster.Name,
+			"description":    cluster.Description,
+			"enabled":        cluster.Enable,
+			"inCluster":      cluster.InCluster,
+			"isDefault":      cluster.IsDefault,
+			"prometheusURL":  cluster.PrometheusURL,
+			"config":         "",
+			"recordTerminal": cluster.RecordTerminal,
+			"archiveEvents":  cluster.ArchiveEvents,
+		}
+		if cluster.NodeTerminalConfig != "" {
+			clusterInfo["nodeTerminalConfig"] = json.RawMessage(cluster.NodeTerminalConfig)
 		}
 
 		if clientSet, exists := cm.clusters[cluster.Name]; exists {
@@ -67,18 +74,27 @@ func (cm *ClusterManager) GetClusterList(c *gin.Context) {
 
 func (cm *ClusterManager) CreateCluster(c *gin.Context) {
 	var req struct {
-		Name          string `json:"name" binding:"required"`
-		Description   string `json:"description"`
-		Config        string `json:"config"`
-		PrometheusURL string `json:"prometheusURL"`
-		InCluster     bool   `json:"inCluster"`
-		IsDefault     bool   `json:"isDefault"`
+		Name           string `json:"name" binding:"required"`
+		Description    string `json:"description"`
+		Config         string `json:"config"`
+		PrometheusURL  string `json:"prometheusURL"`
+		InCluster      bool   `json:"inCluster"`
+		IsDefault      bool   `json:"isDefault"`
+		RecordTerminal bool   `json:"recordTerminal"`
+		ArchiveEvents  bool   `json:"archiveEvents"`
+		// Overrides of the node terminal agent defaults
+		NodeTerminalConfig *common.NodeTerminalConfig `json:"nodeTerminalConfig"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	nodeTerminalConfig, err := marshalNodeTerminalConfig(req.NodeTerminalConfig)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	if _, err := model.GetClusterByName(req.Name); err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "cluster already exists"})
@@ -96,13 +112,16 @@ func (cm *ClusterManager) CreateCluster(c *gin.Context) {
 	}
 
 	cluster := &model.Cluster{
-		Name:          req.Name,
-		Description:   req.Description,
-		Config:        model.SecretString(req.Config),
-		PrometheusURL: req.PrometheusURL,
-		InCluster:     req.InCluster,
-		IsDefault:     req.IsDefault,
-		Enable:        true,
+		Name:               req.Name,
+		Description:        req.Description,
+		Config:             model.SecretString(req.Config),
+		PrometheusURL:      req.PrometheusURL,
+		InCluster:          req.InCluster,
+		IsDefault:          req.IsDefault,
+		Enable:             true,
+		RecordTerminal:     req.RecordTerminal,
+		ArchiveEvents:      req.ArchiveEvents,
+		NodeTerminalConfig: nodeTerminalConfig,
 	}
 
 	if err := model.AddCluster(cluster); err != nil {
@@ -134,12 +153,22 @@ func (cm *ClusterManager) UpdateCluster(c *gin.Context) {
 		InCluster     bool   `json:"inCluster"`
 		IsDefault     bool   `json:"isDefault"`
 		Enabled       bool   `json:"enabled"`
+		// Optional, so clients that do not know the setting leave it unchanged
+		RecordTerminal *bool `json:"recordTerminal"`
+		ArchiveEvents  *bool `json:"archiveEvents"`
+		// Optional, an empty object removes the overrides
+		NodeTerminalConfig *common.NodeTerminalConfig `json:"nodeTerminalConfig"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	nodeTerminalConfig, err := marshalNodeTerminalConfig(req.NodeTerminalConfig)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	cluster, err := model.GetClusterByID(uint(id))
 	if err != nil {
@@ -174,6 +203,18 @@ func (cm *ClusterManager) UpdateCluster(c *gin.Context) {
 		updates["config"] = req.Config
 	}
 
+	if req.RecordTerminal != nil {
+		updates["record_terminal"] = *req.RecordTerminal
+	}
+
+	if req.ArchiveEvents != nil {
+		updates["archive_events"] = *req.ArchiveEvents
+	}
+
+	if req.NodeTerminalConfig != nil {
+		updates["node_terminal_config"] = nodeTerminalConfig
+	}
+
 	if err := model.UpdateCluster(cluster, updates); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -271,3 +312,22 @@ func (cm *ClusterManager) ImportClustersFromKubeconfig(c *gin.Context) {
 	time.Sleep(1 * time.Second)
 	c.JSON(http.StatusCreated, gin.H{"message": fmt.Sprintf("imported %d clusters successfully", importedCount)})
 }
+
+// marshalNodeTerminalConfig validates the node terminal overrides of a cluster and encodes them for the database,
+// an empty config is stored as no overrides
+func marshalNodeTerminalConfig(config *common.NodeTerminalConfig) (string, error) {
+	if config == nil {
+		return "", nil
+	}
+	if err := config.Validate(); err != nil {
+		return "", fmt.Errorf("invalid nodeTerminalConfig: %w", err)
+	}
+	if reflect.DeepEqual(*config, common.NodeTerminalConfig{}) {
+		return "", nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
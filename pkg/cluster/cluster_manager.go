@@ -3,6 +3,7 @@ package cluster
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/xhilmi/kubedash/pkg/kube"
@@ -24,6 +25,16 @@ type ClientSet struct {
 	DiscoveredPrometheusURL string
 	config                  string
 	prometheusURL           string
+	eventArchiver           *eventArchiver
+}
+
+// stop stops the background work of the cluster and its client
+func (cs *ClientSet) stop() {
+	if cs.eventArchiver != nil {
+		cs.eventArchiver.Stop()
+		cs.eventArchiver = nil
+	}
+	cs.K8sClient.Stop(cs.Name)
 }
 
 // GetKubeconfig returns the kubeconfig string for this cluster
@@ -113,6 +124,18 @@ func (cm *ClusterManager) GetClientSet(clusterName string) (*ClientSet, error) {
 	return nil, fmt.Errorf("cluster not found: %s", clusterName)
 }
 
+// ClientSets returns the clients of all loaded clusters, sorted by name
+func (cm *ClusterManager) ClientSets() []*ClientSet {
+	clientSets := make([]*ClientSet, 0, len(cm.clusters))
+	for _, cs := range cm.clusters {
+		clientSets = append(clientSets, cs)
+	}
+	sort.Slice(clientSets, func(i, j int) bool {
+		return clientSets[i].Name < clientSets[j].Name
+	})
+	return clientSets
+}
+
 func ImportClustersFromKubeconfig(kubeconfig *clientcmdapi.Config) int64 {
 	if len(kubeconfig.Contexts) == 0 {
 		return 0
@@ -175,7 +198,7 @@ func syncClusters(cm *ClusterManager) error {
 		if shouldUpdateCluster(current, cluster) {
 			if currentExist {
 				delete(cm.clusters, cluster.Name)
-				current.K8sClient.Stop(cluster.Name)
+				current.stop()
 			}
 			if cluster.Enable {
 				clientSet, err := buildClientSet(cluster)
@@ -186,11 +209,14 @@ func syncClusters(cm *ClusterManager) error {
 				cm.clusters[cluster.Name] = clientSet
 			}
 		}
+		if clientSet, ok := cm.clusters[cluster.Name]; ok {
+			syncEventArchiver(clientSet, cluster.ArchiveEvents)
+		}
 	}
 	for name, clientSet := range cm.clusters {
 		if _, ok := dbClusterMap[name]; !ok {
 			delete(cm.clusters, name)
-			clientSet.K8sClient.Stop(name)
+			clientSet.stop()
 		}
 	}
 
@@ -252,8 +278,12 @@ func NewClusterManager() (*ClusterManager, error) {
 	go func() {
 		ticker := time.NewTicker(1 * time.Minute)
 		defer ticker.Stop()
+		reapTicker := time.NewTicker(nodeAgentReapInterval)
+		defer reapTicker.Stop()
 		for {
 			select {
+			case <-reapTicker.C:
+				reapAllNodeAgents(cm)
 			case <-ticker.C:
 				if err := syncClusters(cm); err != nil {
 					klog.Warningf("Failed to sync clusters: %v", err)
@@ -0,0 +1,185 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// eventArchiveFlushInterval is how often the changed events are written to the database
+	eventArchiveFlushInterval = 10 * time.Second
+	// eventArchiveCleanupInterval is how often archived events past the retention are removed
+	eventArchiveCleanupInterval = time.Hour
+)
+
+// eventArchiver watches the events of a cluster and stores them in the database.
+// Changes are collected by event UID, so an event updated many times between two
+// flushes is written once.
+type eventArchiver struct {
+	cluster string
+	cancel  context.CancelFunc
+
+	mu      sync.Mutex
+	pending map[types.UID]model.ArchivedEvent
+}
+
+func startEventArchiver(clusterName string, clientset *kubernetes.Clientset) *eventArchiver {
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &eventArchiver{
+		cluster: clusterName,
+		cancel:  cancel,
+		pending: make(map[types.UID]model.ArchivedEvent),
+	}
+
+	lw := toolscache.NewListWatchFromClient(clientset.CoreV1().RESTClient(), "events", metav1.NamespaceAll, fields.Everything())
+	informer := toolscache.NewSharedIndexInformer(lw, &corev1.Event{}, 0, toolscache.Indexers{})
+	_ = informer.SetTransform(func(obj interface{}) (interface{}, error) {
+		if e, ok := obj.(*corev1.Event); ok {
+			e.ManagedFields = nil
+		}
+		return obj, nil
+	})
+	if _, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    a.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) { a.enqueue(newObj) },
+	}); err != nil {
+		klog.Warningf("Failed to archive events of cluster %s: %v", clusterName, err)
+	}
+	go informer.Run(ctx.Done())
+	go a.run(ctx)
+
+	klog.Infof("Started event archiver for cluster %s", clusterName)
+	return a
+}
+
+// Stop stops watching and writes the events not flushed yet
+func (a *eventArchiver) Stop() {
+	a.cancel()
+}
+
+func (a *eventArchiver) run(ctx context.Context) {
+	ticker := time.NewTicker(eventArchiveFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.flush()
+			klog.Infof("Stopped event archiver for cluster %s", a.cluster)
+			return
+		case <-ticker.C:
+			a.flush()
+		}
+	}
+}
+
+func (a *eventArchiver) enqueue(obj interface{}) {
+	e, ok := obj.(*corev1.Event)
+	if !ok {
+		return
+	}
+	a.mu.Lock()
+	a.pending[e.UID] = archivedEventFromEvent(a.cluster, e)
+	a.mu.Unlock()
+}
+
+func (a *eventArchiver) flush() {
+	a.mu.Lock()
+	if len(a.pending) == 0 {
+		a.mu.Unlock()
+		return
+	}
+	events := make([]model.ArchivedEvent, 0, len(a.pending))
+	for _, e := range a.pending {
+		events = append(events, e)
+	}
+	a.pending = make(map[types.UID]model.ArchivedEvent)
+	a.mu.Unlock()
+
+	if err := model.UpsertArchivedEvents(events); err != nil {
+		klog.Errorf("Failed to archive %d events of cluster %s: %v", len(events), a.cluster, err)
+	}
+}
+
+// archivedEventFromEvent converts an event to its archive row
+func archivedEventFromEvent(clusterName string, e *corev1.Event) model.ArchivedEvent {
+	first, last := utils.EventTimes(e)
+	source := e.Source.Component
+	if source == "" {
+		source = e.ReportingController
+	}
+
+	return model.ArchivedEvent{
+		ClusterName:        clusterName,
+		UID:                string(e.UID),
+		Namespace:          e.Namespace,
+		Name:               e.Name,
+		InvolvedKind:       e.InvolvedObject.Kind,
+		InvolvedAPIVersion: e.InvolvedObject.APIVersion,
+		InvolvedName:       e.InvolvedObject.Name,
+		InvolvedUID:        string(e.InvolvedObject.UID),
+		Type:               e.Type,
+		Reason:             e.Reason,
+		Message:            e.Message,
+		Source:             source,
+		Count:              utils.EventCount(e),
+		FirstTimestamp:     first,
+		LastTimestamp:      last,
+	}
+}
+
+// syncEventArchiver starts or stops the event archiver of a cluster to match its settings
+func syncEventArchiver(cs *ClientSet, enabled bool) {
+	if enabled && cs.eventArchiver == nil {
+		cs.eventArchiver = startEventArchiver(cs.Name, cs.K8sClient.ClientSet)
+	} else if !enabled && cs.eventArchiver != nil {
+		cs.eventArchiver.Stop()
+		cs.eventArchiver = nil
+	}
+}
+
+// StartEventArchiveCleanup removes archived events past the retention in the background
+func StartEventArchiveCleanup() {
+	if common.EventArchiveRetentionDays == 0 && common.EventArchiveMaxRows == 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(eventArchiveCleanupInterval)
+		defer ticker.Stop()
+		for {
+			cleanupArchivedEvents()
+			<-ticker.C
+		}
+	}()
+}
+
+func cleanupArchivedEvents() {
+	if common.EventArchiveRetentionDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -common.EventArchiveRetentionDays)
+		deleted, err := model.DeleteArchivedEventsBefore(cutoff)
+		if err != nil {
+			klog.Errorf("Failed to clean up archived events: %v", err)
+		} else if deleted > 0 {
+			klog.Infof("Removed %d archived events older than %d days", deleted, common.EventArchiveRetentionDays)
+		}
+	}
+	if common.EventArchiveMaxRows > 0 {
+		deleted, err := model.TrimArchivedEvents(common.EventArchiveMaxRows)
+		if err != nil {
+			klog.Errorf("Failed to trim archived events: %v", err)
+		} else if deleted > 0 {
+			klog.Infof("Removed %d archived events beyond %d per cluster", deleted, common.EventArchiveMaxRows)
+		}
+	}
+}
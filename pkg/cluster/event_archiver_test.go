@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_archivedEventFromEvent(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	created := now.Add(-time.Hour)
+	event := func(mutate func(e *corev1.Event)) *corev1.Event {
+		e := &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "web-1.17f0",
+				Namespace:         "default",
+				UID:               "event-uid",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			InvolvedObject: corev1.ObjectReference{
+				Kind:       "Pod",
+				APIVersion: "v1",
+				Name:       "web-1",
+				UID:        "pod-uid",
+			},
+			Type:    corev1.EventTypeWarning,
+			Reason:  "BackOff",
+			Message: "Back-off restarting failed container",
+		}
+		mutate(e)
+		return e
+	}
+
+	tests := []struct {
+		name       string
+		event      *corev1.Event
+		wantCount  int32
+		wantFirst  time.Time
+		wantLast   time.Time
+		wantSource string
+	}{
+		{
+			name: "legacy event",
+			event: event(func(e *corev1.Event) {
+				e.Count = 7
+				e.FirstTimestamp = metav1.NewTime(now.Add(-30 * time.Minute))
+				e.LastTimestamp = metav1.NewTime(now)
+				e.Source.Component = "kubelet"
+			}),
+			wantCount:  7,
+			wantFirst:  now.Add(-30 * time.Minute),
+			wantLast:   now,
+			wantSource: "kubelet",
+		},
+		{
+			name: "events.k8s.io event with series",
+			event: event(func(e *corev1.Event) {
+				e.EventTime = metav1.NewMicroTime(now.Add(-10 * time.Minute))
+				e.Series = &corev1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(now)}
+				e.ReportingController = "default-scheduler"
+			}),
+			wantCount:  3,
+			wantFirst:  now.Add(-10 * time.Minute),
+			wantLast:   now,
+			wantSource: "default-scheduler",
+		},
+		{
+			name:      "event without timestamps",
+			event:     event(func(e *corev1.Event) {}),
+			wantCount: 1,
+			wantFirst: created,
+			wantLast:  created,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := archivedEventFromEvent("prod", tt.event)
+			assert.Equal(t, "prod", got.ClusterName)
+			assert.Equal(t, "event-uid", got.UID)
+			assert.Equal(t, "Pod", got.InvolvedKind)
+			assert.Equal(t, "web-1", got.InvolvedName)
+			assert.Equal(t, "BackOff", got.Reason)
+			assert.Equal(t, tt.wantCount, got.Count)
+			assert.True(t, tt.wantFirst.Equal(got.FirstTimestamp), "first timestamp %v", got.FirstTimestamp)
+			assert.True(t, tt.wantLast.Equal(got.LastTimestamp), "last timestamp %v", got.LastTimestamp)
+			assert.Equal(t, tt.wantSource, got.Source)
+		})
+	}
+}
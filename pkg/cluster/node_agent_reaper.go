@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// nodeAgentReapInterval is how often the clusters are checked for node terminal agents left behind
+	nodeAgentReapInterval = 5 * time.Minute
+	// nodeAgentStaleAfter is how long an agent may go without a heartbeat before its instance is considered gone
+	nodeAgentStaleAfter = 3 * common.NodeTerminalHeartbeatInterval
+)
+
+// reapNodeAgents deletes the node terminal agent pods of a cluster that outlived their session,
+// because Kite was restarted or crashed before it could clean them up
+func reapNodeAgents(clusterName string, cs *ClientSet) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pods, err := cs.K8sClient.ClientSet.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: common.NodeTerminalAgentLabel + "=true",
+	})
+	if err != nil {
+		klog.Warningf("Failed to list node terminal agents in cluster %s: %v", clusterName, err)
+		return
+	}
+	now := time.Now()
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		reason := nodeAgentReapReason(pod, now)
+		if reason == "" {
+			continue
+		}
+		if err := cs.K8sClient.ClientSet.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+			klog.Warningf("Failed to delete node terminal agent %s/%s in cluster %s: %v", pod.Namespace, pod.Name, clusterName, err)
+			continue
+		}
+		klog.Infof("Deleted node terminal agent %s/%s in cluster %s: %s", pod.Namespace, pod.Name, clusterName, reason)
+	}
+}
+
+// nodeAgentReapReason returns why an agent pod should be deleted, or an empty string to keep it
+func nodeAgentReapReason(pod *corev1.Pod, now time.Time) string {
+	if pod.DeletionTimestamp != nil {
+		return ""
+	}
+	if utils.IsPodErrorOrSuccess(pod) {
+		return "agent has terminated"
+	}
+	if now.Sub(pod.CreationTimestamp.Time) > common.NodeTerminalSessionTTL {
+		return fmt.Sprintf("older than the session TTL %s", common.NodeTerminalSessionTTL)
+	}
+	lastActive := pod.CreationTimestamp.Time
+	if value, ok := pod.Annotations[common.NodeTerminalLastActiveAnnotation]; ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			lastActive = t
+		}
+	}
+	if now.Sub(lastActive) > nodeAgentStaleAfter {
+		return fmt.Sprintf("instance %s stopped refreshing it", pod.Labels[common.NodeTerminalInstanceLabel])
+	}
+	return ""
+}
+
+// reapAllNodeAgents checks every cluster in the background, it is called from the
+// cluster manager loop so reading the cluster map does not race with syncClusters
+func reapAllNodeAgents(cm *ClusterManager) {
+	clusters := make(map[string]*ClientSet, len(cm.clusters))
+	for name, cs := range cm.clusters {
+		clusters[name] = cs
+	}
+	go func() {
+		for name, cs := range clusters {
+			reapNodeAgents(name, cs)
+		}
+	}()
+}
@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xhilmi/kubedash/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_nodeAgentReapReason(t *testing.T) {
+	now := time.Now()
+	agent := func(created, lastActive time.Time, phase corev1.PodPhase) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "kite-node-terminal-agent-node1-abcde",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					common.NodeTerminalAgentLabel:    "true",
+					common.NodeTerminalInstanceLabel: "kite-abcdef",
+				},
+				Annotations: map[string]string{},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+		if !lastActive.IsZero() {
+			pod.Annotations[common.NodeTerminalLastActiveAnnotation] = lastActive.UTC().Format(time.RFC3339)
+		}
+		return pod
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{
+			name: "active session is kept",
+			pod:  agent(now.Add(-time.Hour), now.Add(-30*time.Second), corev1.PodRunning),
+			want: false,
+		},
+		{
+			name: "new agent without heartbeat is kept",
+			pod:  agent(now.Add(-time.Minute), time.Time{}, corev1.PodPending),
+			want: false,
+		},
+		{
+			name: "terminated agent is reaped",
+			pod:  agent(now.Add(-time.Minute), now, corev1.PodSucceeded),
+			want: true,
+		},
+		{
+			name: "agent older than the session TTL is reaped",
+			pod:  agent(now.Add(-common.NodeTerminalSessionTTL-time.Minute), now, corev1.PodRunning),
+			want: true,
+		},
+		{
+			name: "agent of a gone instance is reaped",
+			pod:  agent(now.Add(-time.Hour), now.Add(-10*time.Minute), corev1.PodRunning),
+			want: true,
+		},
+		{
+			name: "agent being deleted is skipped",
+			pod: func() *corev1.Pod {
+				pod := agent(now.Add(-time.Hour), now.Add(-10*time.Minute), corev1.PodRunning)
+				pod.DeletionTimestamp = &metav1.Time{Time: now}
+				return pod
+			}(),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nodeAgentReapReason(tt.pod, now) != "")
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/klog/v2"
 )
 
@@ -14,8 +16,14 @@ const (
 
 	NodeTerminalPodName = "kite-node-terminal-agent"
 
+	// DebugContainerPrefix names the ephemeral containers of pod debug sessions
+	DebugContainerPrefix = "kite-debug"
+
 	KubectlAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
 
+	// ArchivedEventAnnotation marks events served from the event archive instead of the API server
+	ArchivedEventAnnotation = "kite.io/archived"
+
 	// db connection max idle time
 	DBMaxIdleTime  = 10 * time.Minute
 	DBMaxOpenConns = 100
@@ -26,6 +34,18 @@ const (
 
 	// Default helm max revisions to fetch
 	DefaultHelmMaxRevisions = 20
+
+	// Default number of days terminal recordings are kept
+	DefaultTerminalRecordingRetentionDays = 90
+
+	// Default cap of a single file copy to or from a container (100Mi)
+	DefaultFileCopyMaxSize = 100 << 20
+
+	// Default number of days archived Kubernetes events are kept
+	DefaultEventArchiveRetentionDays = 7
+
+	// Default number of archived events kept per cluster
+	DefaultEventArchiveMaxRows = 100000
 )
 
 var (
@@ -39,6 +59,22 @@ var (
 	DBType            = "sqlite"
 	DBDSN             = "dev.db"
 
+	// Node terminal agent defaults (configurable via NODE_TERMINAL_* envs), clusters can override them
+	NodeTerminalNamespace        = "kube-system"
+	NodeTerminalCPURequest       = "10m"
+	NodeTerminalMemoryRequest    = "32Mi"
+	NodeTerminalCPULimit         = "1"
+	NodeTerminalMemoryLimit      = "512Mi"
+	NodeTerminalImagePullSecrets []string
+	NodeTerminalNodeSelector     map[string]string
+	NodeTerminalIdleTimeout      = "30m"
+
+	// Agent pods older than this are deleted by the reaper (configurable via NODE_TERMINAL_SESSION_TTL env)
+	NodeTerminalSessionTTL = 12 * time.Hour
+
+	// InstanceID identifies this Kite process in the labels of the pods it creates
+	InstanceID = newInstanceID()
+
 	KiteEncryptKey = "kite-default-encryption-key-change-in-production"
 
 	AnonymousUserEnabled = false
@@ -55,6 +91,29 @@ var (
 
 	// Helm max revisions to fetch (configurable via HELM_MAX_REVISIONS env)
 	HelmMaxRevisions = DefaultHelmMaxRevisions
+
+	// CRDs (<plural>.<group>) whose custom resources are included in global search (configurable via SEARCH_CRDS env)
+	SearchCRDs []string
+
+	// Directory of the terminal session recordings (configurable via TERMINAL_RECORDING_DIR env)
+	TerminalRecordingDir = "recordings"
+
+	// Days terminal recordings are kept, 0 keeps them forever (configurable via TERMINAL_RECORDING_RETENTION_DAYS env)
+	TerminalRecordingRetentionDays = DefaultTerminalRecordingRetentionDays
+
+	// Maximum bytes of a file copy to or from a container (configurable via FILE_COPY_MAX_SIZE env)
+	FileCopyMaxSize int64 = DefaultFileCopyMaxSize
+
+	// Days archived events are kept, 0 keeps them forever (configurable via EVENT_ARCHIVE_RETENTION_DAYS env)
+	EventArchiveRetentionDays = DefaultEventArchiveRetentionDays
+
+	// Archived events kept per cluster, the oldest are removed first, 0 for no limit
+	// (configurable via EVENT_ARCHIVE_MAX_ROWS env)
+	EventArchiveMaxRows = DefaultEventArchiveMaxRows
+
+	// Let 'get' grant 'watch' for roles written before watch was enforced
+	// (configurable via RBAC_GET_GRANTS_WATCH env)
+	RBACGetGrantsWatch = false
 )
 
 func LoadEnvs() {
@@ -74,6 +133,8 @@ func LoadEnvs() {
 		NodeTerminalImage = nodeTerminalImage
 	}
 
+	loadNodeTerminalEnvs()
+
 	if dbDSN := os.Getenv("DB_DSN"); dbDSN != "" {
 		DBDSN = dbDSN
 	}
@@ -131,4 +192,115 @@ func LoadEnvs() {
 			klog.Warningf("Invalid HELM_MAX_REVISIONS value: %s, using default %d", v, DefaultHelmMaxRevisions)
 		}
 	}
+
+	if v := os.Getenv("SEARCH_CRDS"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				SearchCRDs = append(SearchCRDs, name)
+			}
+		}
+		klog.Infof("Global search includes custom resources: %v", SearchCRDs)
+	}
+
+	if v := os.Getenv("TERMINAL_RECORDING_DIR"); v != "" {
+		TerminalRecordingDir = v
+	}
+
+	if v := os.Getenv("TERMINAL_RECORDING_RETENTION_DAYS"); v != "" {
+		if days, err := strconv.Atoi(v); err == nil && days >= 0 {
+			TerminalRecordingRetentionDays = days
+			klog.Infof("Terminal recording retention set to %d days", TerminalRecordingRetentionDays)
+		} else {
+			klog.Warningf("Invalid TERMINAL_RECORDING_RETENTION_DAYS value: %s, using default %d days", v, DefaultTerminalRecordingRetentionDays)
+		}
+	}
+
+	if v := os.Getenv("FILE_COPY_MAX_SIZE"); v != "" {
+		if size, err := resource.ParseQuantity(v); err == nil && size.Value() > 0 {
+			FileCopyMaxSize = size.Value()
+			klog.Infof("File copy max size set to %s", v)
+		} else {
+			klog.Warningf("Invalid FILE_COPY_MAX_SIZE value: %s, using default %d bytes", v, DefaultFileCopyMaxSize)
+		}
+	}
+
+	if v := os.Getenv("EVENT_ARCHIVE_RETENTION_DAYS"); v != "" {
+		if days, err := strconv.Atoi(v); err == nil && days >= 0 {
+			EventArchiveRetentionDays = days
+			klog.Infof("Event archive retention set to %d days", EventArchiveRetentionDays)
+		} else {
+			klog.Warningf("Invalid EVENT_ARCHIVE_RETENTION_DAYS value: %s, using default %d days", v, DefaultEventArchiveRetentionDays)
+		}
+	}
+
+	if v := os.Getenv("EVENT_ARCHIVE_MAX_ROWS"); v != "" {
+		if rows, err := strconv.Atoi(v); err == nil && rows >= 0 {
+			EventArchiveMaxRows = rows
+			klog.Infof("Event archive max rows set to %d", EventArchiveMaxRows)
+		} else {
+			klog.Warningf("Invalid EVENT_ARCHIVE_MAX_ROWS value: %s, using default %d", v, DefaultEventArchiveMaxRows)
+		}
+	}
+
+	if v := os.Getenv("RBAC_GET_GRANTS_WATCH"); v == "true" {
+		RBACGetGrantsWatch = true
+		klog.Infof("RBAC 'get' grants 'watch'")
+	}
+}
+
+func loadNodeTerminalEnvs() {
+	if v := os.Getenv("NODE_TERMINAL_NAMESPACE"); v != "" {
+		NodeTerminalNamespace = v
+	}
+	for env, target := range map[string]*string{
+		"NODE_TERMINAL_CPU_REQUEST":    &NodeTerminalCPURequest,
+		"NODE_TERMINAL_MEMORY_REQUEST": &NodeTerminalMemoryRequest,
+		"NODE_TERMINAL_CPU_LIMIT":      &NodeTerminalCPULimit,
+		"NODE_TERMINAL_MEMORY_LIMIT":   &NodeTerminalMemoryLimit,
+		"NODE_TERMINAL_IDLE_TIMEOUT":   &NodeTerminalIdleTimeout,
+	} {
+		if v, ok := os.LookupEnv(env); ok {
+			*target = strings.TrimSpace(v)
+		}
+	}
+	if v := os.Getenv("NODE_TERMINAL_IMAGE_PULL_SECRETS"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				NodeTerminalImagePullSecrets = append(NodeTerminalImagePullSecrets, name)
+			}
+		}
+	}
+	if v := os.Getenv("NODE_TERMINAL_NODE_SELECTOR"); v != "" {
+		NodeTerminalNodeSelector = make(map[string]string)
+		for _, pair := range strings.Split(v, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			if key != "" {
+				NodeTerminalNodeSelector[key] = value
+			}
+		}
+	}
+	if v := os.Getenv("NODE_TERMINAL_SESSION_TTL"); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil && ttl > 0 {
+			NodeTerminalSessionTTL = ttl
+		} else {
+			klog.Warningf("Invalid NODE_TERMINAL_SESSION_TTL value: %s, using default %s", v, NodeTerminalSessionTTL)
+		}
+	}
+	if err := DefaultNodeTerminalConfig().Validate(); err != nil {
+		klog.Fatalf("Invalid node terminal settings: %v", err)
+	}
+}
+
+// newInstanceID returns the host name, which is the pod name when running in Kubernetes,
+// with a random suffix so restarts in the same pod get a new ID
+func newInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "kite"
+	}
+	host = strings.ToLower(host)
+	if len(host) > 56 {
+		host = host[:56]
+	}
+	return strings.Trim(host, "-.") + "-" + rand.String(6)
 }
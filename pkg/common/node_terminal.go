@@ -0,0 +1,121 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// NodeTerminalAgentLabel marks node terminal agent pods, the reaper only deletes pods with this label
+	NodeTerminalAgentLabel = "kite.io/node-terminal-agent"
+	// NodeTerminalInstanceLabel records the Kite instance that created an agent pod
+	NodeTerminalInstanceLabel = "kite.io/instance"
+	// NodeTerminalLastActiveAnnotation is refreshed by the owning session, a stale value means the instance is gone
+	NodeTerminalLastActiveAnnotation = "kite.io/last-active"
+
+	// NodeTerminalHeartbeatInterval is how often a session refreshes the last active annotation
+	NodeTerminalHeartbeatInterval = time.Minute
+)
+
+// NodeTerminalConfig configures the node terminal agent pods. The defaults come from the
+// environment, a cluster can override any of them in its settings.
+type NodeTerminalConfig struct {
+	Namespace        string            `json:"namespace,omitempty"`
+	Image            string            `json:"image,omitempty"`
+	CPURequest       string            `json:"cpuRequest,omitempty"`
+	MemoryRequest    string            `json:"memoryRequest,omitempty"`
+	CPULimit         string            `json:"cpuLimit,omitempty"`
+	MemoryLimit      string            `json:"memoryLimit,omitempty"`
+	ImagePullSecrets []string          `json:"imagePullSecrets,omitempty"`
+	NodeSelector     map[string]string `json:"nodeSelector,omitempty"`
+	// IdleTimeout closes sessions without input for this long, as a Go duration, "0" disables it
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+}
+
+// DefaultNodeTerminalConfig returns the node terminal settings from the environment
+func DefaultNodeTerminalConfig() NodeTerminalConfig {
+	return NodeTerminalConfig{
+		Namespace:        NodeTerminalNamespace,
+		Image:            NodeTerminalImage,
+		CPURequest:       NodeTerminalCPURequest,
+		MemoryRequest:    NodeTerminalMemoryRequest,
+		CPULimit:         NodeTerminalCPULimit,
+		MemoryLimit:      NodeTerminalMemoryLimit,
+		ImagePullSecrets: NodeTerminalImagePullSecrets,
+		NodeSelector:     NodeTerminalNodeSelector,
+		IdleTimeout:      NodeTerminalIdleTimeout,
+	}
+}
+
+// Merge returns the config with the non-empty fields of override applied
+func (c NodeTerminalConfig) Merge(override NodeTerminalConfig) NodeTerminalConfig {
+	if override.Namespace != "" {
+		c.Namespace = override.Namespace
+	}
+	if override.Image != "" {
+		c.Image = override.Image
+	}
+	if override.CPURequest != "" {
+		c.CPURequest = override.CPURequest
+	}
+	if override.MemoryRequest != "" {
+		c.MemoryRequest = override.MemoryRequest
+	}
+	if override.CPULimit != "" {
+		c.CPULimit = override.CPULimit
+	}
+	if override.MemoryLimit != "" {
+		c.MemoryLimit = override.MemoryLimit
+	}
+	if override.ImagePullSecrets != nil {
+		c.ImagePullSecrets = override.ImagePullSecrets
+	}
+	if override.NodeSelector != nil {
+		c.NodeSelector = override.NodeSelector
+	}
+	if override.IdleTimeout != "" {
+		c.IdleTimeout = override.IdleTimeout
+	}
+	return c
+}
+
+// Validate checks the namespace, resource quantities and idle timeout
+func (c NodeTerminalConfig) Validate() error {
+	if c.Namespace != "" {
+		if errs := validation.IsDNS1123Label(c.Namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %s", c.Namespace, errs[0])
+		}
+	}
+	for name, value := range map[string]string{
+		"cpuRequest":    c.CPURequest,
+		"memoryRequest": c.MemoryRequest,
+		"cpuLimit":      c.CPULimit,
+		"memoryLimit":   c.MemoryLimit,
+	} {
+		if value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(value); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+	}
+	if _, err := c.IdleTimeoutDuration(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// IdleTimeoutDuration parses the idle timeout, zero means sessions never time out
+func (c NodeTerminalConfig) IdleTimeoutDuration() (time.Duration, error) {
+	if c.IdleTimeout == "" || c.IdleTimeout == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.IdleTimeout)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid idleTimeout %q", c.IdleTimeout)
+	}
+	return d, nil
+}
@@ -12,14 +12,22 @@ const (
 	VerbDelete Verb = "delete"
 	VerbLog    Verb = "log"
 	VerbExec   Verb = "exec"
+	VerbDebug  Verb = "debug" // Start an ephemeral debug container in a pod, independent of exec
+	VerbPortForward Verb = "portforward" // Forward TCP connections to a pod port, independent of exec
+	VerbCopy        Verb = "cp"          // Copy files to and from containers, granted by exec
 	
 	// Fine-grained deployment operations
 	VerbRestart Verb = "restart" // Restart deployment only
 	VerbScale   Verb = "scale"   // Scale deployment replicas only
 	VerbEdit    Verb = "edit"    // Full YAML edit capability
+	VerbPause   Verb = "pause"   // Pause or resume a deployment rollout
 	
 	// FluxCD operations
 	VerbRollback Verb = "rollback" // Rollback HelmRelease to previous revision
+
+	// CronJob operations
+	VerbTrigger Verb = "trigger" // Run a CronJob now
+	VerbSuspend Verb = "suspend" // Suspend or resume a CronJob
 )
 
 type Role struct {
@@ -11,6 +11,7 @@ type SearchResult struct {
 	Namespace    string `json:"namespace,omitempty"`
 	ResourceType string `json:"resourceType"`
 	CreatedAt    string `json:"createdAt"`
+	Cluster      string `json:"cluster,omitempty"`
 }
 
 type RelatedResource struct {
@@ -20,6 +21,37 @@ type RelatedResource struct {
 	Namespace  string `json:"namespace,omitempty"`
 }
 
+// Health states of topology nodes
+const (
+	HealthHealthy     = "healthy"
+	HealthProgressing = "progressing"
+	HealthDegraded    = "degraded"
+	HealthMissing     = "missing" // referenced but not found
+	HealthUnknown     = "unknown"
+)
+
+type TopologyNode struct {
+	ID         string `json:"id"` // <type>/<namespace>/<name>
+	Type       string `json:"type"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Health     string `json:"health"`
+	Status     string `json:"status,omitempty"`
+}
+
+type TopologyEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"` // owns, selects, routes, parent, mounts, binds, storageclass, scales
+}
+
+type Topology struct {
+	Namespace string         `json:"namespace"`
+	Nodes     []TopologyNode `json:"nodes"`
+	Edges     []TopologyEdge `json:"edges"`
+}
+
 type Resource struct {
 	Allocatable int64 `json:"allocatable"`
 	Requested   int64 `json:"requested"`
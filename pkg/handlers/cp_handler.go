@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	"k8s.io/klog/v2"
+)
+
+// errCopyTooLarge is returned when a copy exceeds common.FileCopyMaxSize
+var errCopyTooLarge = errors.New("copy exceeds the maximum size")
+
+type CopyHandler struct {
+}
+
+func NewCopyHandler() *CopyHandler {
+	return &CopyHandler{}
+}
+
+// copyRequest holds the common parameters of uploads and downloads
+type copyRequest struct {
+	cs        *cluster.ClientSet
+	user      model.User
+	namespace string
+	podName   string
+	container string
+	path      string
+}
+
+func (h *CopyHandler) parseRequest(c *gin.Context) (*copyRequest, bool) {
+	req := &copyRequest{
+		cs:        c.MustGet("cluster").(*cluster.ClientSet),
+		user:      c.MustGet("user").(model.User),
+		namespace: c.Param("namespace"),
+		podName:   c.Param("podName"),
+		container: c.Query("container"),
+		path:      c.Query("path"),
+	}
+	if req.path == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path is required"})
+		return nil, false
+	}
+	if !rbac.CanAccess(req.user, "pods", string(common.VerbCopy), req.cs.Name, req.namespace) {
+		c.JSON(http.StatusForbidden, gin.H{"error": rbac.NoAccess(req.user.Key(), string(common.VerbCopy), "pods", req.namespace, req.cs.Name)})
+		return nil, false
+	}
+	return req, true
+}
+
+// Download handles GET /cp/:namespace/:podName/download?path=. A file is sent as is,
+// a directory as a tar archive. Transfers beyond FILE_COPY_MAX_SIZE are aborted.
+func (h *CopyHandler) Download(c *gin.Context) {
+	req, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(kube.CopyFromPod(ctx, req.cs.K8sClient, req.namespace, req.podName, req.container, req.path, pw))
+	}()
+	// Stop tar in the container when the transfer ends early
+	defer func() {
+		_ = pr.Close()
+	}()
+
+	tr := tar.NewReader(pr)
+	header, err := tr.Next()
+	if err != nil {
+		if err == io.EOF {
+			err = fmt.Errorf("%s is empty", req.path)
+		}
+		h.recordCopy(req, "download", 0, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to copy %s: %v", req.path, err)})
+		return
+	}
+
+	var written int64
+	switch header.Typeflag {
+	case tar.TypeReg:
+		if header.Size > common.FileCopyMaxSize {
+			err = fmt.Errorf("%w of %d bytes, %s has %d bytes", errCopyTooLarge, common.FileCopyMaxSize, req.path, header.Size)
+			h.recordCopy(req, "download", 0, err)
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(header.Name)))
+		c.Header("Content-Type", "application/octet-stream")
+		c.Header("Content-Length", fmt.Sprint(header.Size))
+		c.Status(http.StatusOK)
+		written, err = io.Copy(c.Writer, tr)
+	case tar.TypeDir:
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(path.Clean(req.path))+".tar"))
+		c.Header("Content-Type", "application/x-tar")
+		c.Status(http.StatusOK)
+		written, err = copyTarArchive(c.Writer, tr, header)
+	default:
+		err = fmt.Errorf("%s is not a regular file or directory", req.path)
+		h.recordCopy(req, "download", 0, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		// The response has started, the client sees a truncated transfer
+		klog.Warningf("Download of %s from pod %s/%s aborted: %v", req.path, req.namespace, req.podName, err)
+	}
+	h.recordCopy(req, "download", written, err)
+}
+
+// copyTarArchive rewrites the archive of a directory, starting with its first header,
+// and returns the bytes of file content written
+func copyTarArchive(w io.Writer, tr *tar.Reader, header *tar.Header) (int64, error) {
+	tw := tar.NewWriter(w)
+	var written int64
+	for {
+		if written+header.Size > common.FileCopyMaxSize {
+			return written, fmt.Errorf("%w of %d bytes", errCopyTooLarge, common.FileCopyMaxSize)
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return written, err
+		}
+		if header.Typeflag == tar.TypeReg {
+			n, err := io.Copy(tw, tr)
+			written += n
+			if err != nil {
+				return written, err
+			}
+		}
+		var err error
+		header, err = tr.Next()
+		if err == io.EOF {
+			return written, tw.Close()
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// Upload handles POST /cp/:namespace/:podName/upload?path=, path is the directory in the container.
+// A body of type application/x-tar is extracted as is, which keeps directories, otherwise the
+// files of the multipart form field "file" are written into the directory.
+func (h *CopyHandler) Upload(c *gin.Context) {
+	req, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+	if c.Request.ContentLength > common.FileCopyMaxSize {
+		err := fmt.Errorf("%w of %d bytes", errCopyTooLarge, common.FileCopyMaxSize)
+		h.recordCopy(req, "upload", 0, err)
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, common.FileCopyMaxSize)
+
+	var archive io.Reader
+	var files []string
+	var size int64
+	if strings.HasPrefix(c.ContentType(), "application/x-tar") {
+		archive = &countingReader{r: c.Request.Body}
+	} else {
+		form, err := c.MultipartForm()
+		if err != nil {
+			status := http.StatusBadRequest
+			if isMaxBytesError(err) {
+				status, err = http.StatusRequestEntityTooLarge, fmt.Errorf("%w of %d bytes", errCopyTooLarge, common.FileCopyMaxSize)
+			}
+			h.recordCopy(req, "upload", 0, err)
+			c.JSON(status, gin.H{"error": "invalid upload: " + err.Error()})
+			return
+		}
+		uploads := form.File["file"]
+		if len(uploads) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no file uploaded"})
+			return
+		}
+		pr, pw := io.Pipe()
+		// Unblock the tar writer if the copy fails before reading all of stdin
+		defer pr.Close()
+		go func() {
+			tw := tar.NewWriter(pw)
+			for _, upload := range uploads {
+				file, err := upload.Open()
+				if err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+				err = tw.WriteHeader(&tar.Header{
+					Name:    path.Base(upload.Filename),
+					Mode:    0o644,
+					Size:    upload.Size,
+					ModTime: time.Now(),
+				})
+				if err == nil {
+					_, err = io.Copy(tw, file)
+				}
+				_ = file.Close()
+				if err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+			}
+			pw.CloseWithError(tw.Close())
+		}()
+		archive = pr
+		for _, upload := range uploads {
+			files = append(files, path.Base(upload.Filename))
+			size += upload.Size
+		}
+	}
+
+	err := kube.CopyToPod(c.Request.Context(), req.cs.K8sClient, req.namespace, req.podName, req.container, req.path, archive)
+	status := http.StatusInternalServerError
+	if counter, ok := archive.(*countingReader); ok {
+		size = counter.n
+		// exec closes stdin on read errors, so tar only sees a truncated archive
+		if isMaxBytesError(counter.err) {
+			status, err = http.StatusRequestEntityTooLarge, fmt.Errorf("%w of %d bytes", errCopyTooLarge, common.FileCopyMaxSize)
+		}
+	}
+	if err != nil {
+		h.recordCopy(req, "upload", size, err)
+		c.JSON(status, gin.H{"error": fmt.Sprintf("failed to copy to %s: %v", req.path, err)})
+		return
+	}
+	h.recordCopy(req, "upload", size, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "uploaded", "path": req.path, "files": files, "bytes": size})
+}
+
+// recordCopy writes the transfer to the resource history of the pod
+func (h *CopyHandler) recordCopy(req *copyRequest, operation string, bytes int64, copyErr error) {
+	details, _ := json.Marshal(map[string]interface{}{
+		"container": req.container,
+		"path":      req.path,
+		"bytes":     bytes,
+	})
+	errMsg := ""
+	if copyErr != nil {
+		errMsg = copyErr.Error()
+	}
+	history := model.ResourceHistory{
+		ClusterName:   req.cs.Name,
+		ResourceType:  "pods",
+		ResourceName:  req.podName,
+		Namespace:     req.namespace,
+		OperationType: operation,
+		PreviousYAML:  string(details), // Store action details in PreviousYAML field
+		Success:       copyErr == nil,
+		ErrorMessage:  errMsg,
+		OperatorID:    req.user.ID,
+	}
+	if err := model.DB.Create(&history).Error; err != nil {
+		klog.Errorf("Failed to create resource history: %v", err)
+	}
+	klog.Infof("User %s copy %s of %s in pod %s/%s in cluster %s: %d bytes, error: %v", req.user.Key(), operation, req.path, req.namespace, req.podName, req.cs.Name, bytes, copyErr)
+}
+
+func isMaxBytesError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// countingReader counts the bytes read through it and keeps the read error
+type countingReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// uploadGoroutines counts the goroutines still building an upload archive
+func uploadGoroutines() int {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	return strings.Count(string(buf), "(*CopyHandler).Upload.func")
+}
+
+func TestUploadToMissingPod(t *testing.T) {
+	setupTestDB(t, &model.ResourceHistory{})
+
+	// The API server rejects the exec before any stdin is read
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404,"message":"pods \"missing\" not found"}`))
+	}))
+	t.Cleanup(server.Close)
+	config := &rest.Config{Host: server.URL}
+	clientset, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+	cs := &cluster.ClientSet{
+		Name:      "test",
+		K8sClient: &kube.K8sClient{ClientSet: clientset, Configuration: config},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("cluster", cs)
+		c.Set("user", model.User{
+			Username: "admin",
+			Roles: []common.Role{{
+				Name:       "admin",
+				Clusters:   []string{"*"},
+				Namespaces: []string{"*"},
+				Resources:  []string{"*"},
+				Verbs:      []string{"*"},
+			}},
+		})
+		c.Next()
+	})
+	r.POST("/cp/:namespace/:podName/upload", NewCopyHandler().Upload)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "data.txt")
+	require.NoError(t, err)
+	_, err = part.Write(bytes.Repeat([]byte("x"), 64*1024))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/cp/default/missing/upload?path=/tmp", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+
+	assert.Eventually(t, func() bool { return uploadGoroutines() == 0 }, 2*time.Second, 10*time.Millisecond,
+		"the goroutine building the upload archive did not exit")
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// logDownloadOptions are the parsed query parameters of a log download
+type logDownloadOptions struct {
+	logOptions corev1.PodLogOptions
+	// until drops the lines logged after this time, the API server only supports a start time
+	until      *time.Time
+	timestamps bool
+	gzip       bool
+}
+
+// parseLogDownloadOptions reads container, previous, timestamps, sinceSeconds, sinceTime,
+// untilTime, tailLines and gzip from the query. Times are RFC3339, tailLines defaults to the whole log.
+func parseLogDownloadOptions(c *gin.Context) (*logDownloadOptions, error) {
+	opts := &logDownloadOptions{
+		timestamps: c.Query("timestamps") == "true",
+		gzip:       c.Query("gzip") == "true",
+	}
+	opts.logOptions.Container = c.Query("container")
+	opts.logOptions.Previous = c.Query("previous") == "true"
+	opts.logOptions.Timestamps = opts.timestamps
+
+	if v := c.Query("tailLines"); v != "" && v != "-1" {
+		tail, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || tail < 0 {
+			return nil, fmt.Errorf("invalid tailLines parameter")
+		}
+		opts.logOptions.TailLines = &tail
+	}
+	if v := c.Query("sinceSeconds"); v != "" {
+		since, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || since <= 0 {
+			return nil, fmt.Errorf("invalid sinceSeconds parameter")
+		}
+		opts.logOptions.SinceSeconds = &since
+	}
+	if v := c.Query("sinceTime"); v != "" {
+		if opts.logOptions.SinceSeconds != nil {
+			return nil, fmt.Errorf("sinceSeconds and sinceTime cannot be used together")
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sinceTime parameter: %v", err)
+		}
+		sinceTime := metav1.NewTime(t)
+		opts.logOptions.SinceTime = &sinceTime
+	}
+	if v := c.Query("untilTime"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid untilTime parameter: %v", err)
+		}
+		if opts.logOptions.SinceTime != nil && t.Before(opts.logOptions.SinceTime.Time) {
+			return nil, fmt.Errorf("untilTime must not be before sinceTime")
+		}
+		opts.until = &t
+		// The timestamps are needed to find where the range ends, they are stripped again if not requested
+		opts.logOptions.Timestamps = true
+	}
+	return opts, nil
+}
+
+// DownloadLogs handles GET /logs/:namespace/:podName/download and returns the logs as a file.
+// With podName _all and a labelSelector it returns a tar archive with one <pod>/<container>.log per container.
+// Requires 'log' verb permission on pods
+func (h *LogsHandler) DownloadLogs(c *gin.Context) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
+	namespace := c.Param("namespace")
+	podName := c.Param("podName")
+
+	if !rbac.CanAccess(user, "pods", string(common.VerbLog), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbLog), "pods", namespace, cs.Name),
+		})
+		return
+	}
+
+	opts, err := parseLogDownloadOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	labelSelector := c.Query("labelSelector")
+	if podName == "_all" {
+		if labelSelector == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "labelSelector is required to download the logs of multiple pods"})
+			return
+		}
+		selector, err := labels.Parse(labelSelector)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid labelSelector parameter: " + err.Error()})
+			return
+		}
+		h.downloadLogArchive(c, cs, namespace, selector, opts)
+		return
+	}
+
+	ctx := c.Request.Context()
+	stream, err := cs.K8sClient.ClientSet.CoreV1().Pods(namespace).GetLogs(podName, &opts.logOptions).Stream(ctx)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var apiStatus apierrors.APIStatus
+		if errors.As(err, &apiStatus) && apiStatus.Status().Code != 0 {
+			status = int(apiStatus.Status().Code)
+		}
+		c.JSON(status, gin.H{"error": "Failed to get pod logs: " + err.Error()})
+		return
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	filename := podName
+	if opts.logOptions.Container != "" {
+		filename += "-" + opts.logOptions.Container
+	}
+	filename += ".log"
+
+	var w io.Writer = c.Writer
+	if opts.gzip {
+		filename += ".gz"
+		c.Header("Content-Type", "application/gzip")
+		gz := gzip.NewWriter(c.Writer)
+		defer func() {
+			_ = gz.Close()
+		}()
+		w = gz
+	} else {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	if _, err := copyLogLines(w, stream, opts); err != nil && !errors.Is(err, context.Canceled) {
+		klog.Errorf("Failed to download logs of pod %s/%s: %v", namespace, podName, err)
+	}
+}
+
+// downloadLogArchive writes the logs of every container of the matching pods to a tar archive.
+// Each log is spooled to a temporary file first because tar headers need the size up front.
+func (h *LogsHandler) downloadLogArchive(c *gin.Context, cs *cluster.ClientSet, namespace string, selector labels.Selector, opts *logDownloadOptions) {
+	ctx := c.Request.Context()
+	podList := &corev1.PodList{}
+	if err := cs.K8sClient.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list pods: " + err.Error()})
+		return
+	}
+	if len(podList.Items) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no pods match the label selector"})
+		return
+	}
+
+	spool, err := os.CreateTemp("", "kite-logs-*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create temporary file: " + err.Error()})
+		return
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	filename := namespace + "-logs.tar"
+	var w io.Writer = c.Writer
+	if opts.gzip {
+		filename += ".gz"
+		c.Header("Content-Type", "application/gzip")
+		gz := gzip.NewWriter(c.Writer)
+		defer func() {
+			_ = gz.Close()
+		}()
+		w = gz
+	} else {
+		c.Header("Content-Type", "application/x-tar")
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	tw := tar.NewWriter(w)
+	defer func() {
+		_ = tw.Close()
+	}()
+
+	for _, pod := range podList.Items {
+		containers := make([]string, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+		for _, container := range pod.Spec.InitContainers {
+			containers = append(containers, container.Name)
+		}
+		for _, container := range pod.Spec.Containers {
+			containers = append(containers, container.Name)
+		}
+
+		for _, container := range containers {
+			if opts.logOptions.Container != "" && container != opts.logOptions.Container {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return
+			}
+
+			logOptions := opts.logOptions
+			logOptions.Container = container
+			size, err := spoolPodLogs(ctx, cs, pod.Namespace, pod.Name, &logOptions, opts, spool)
+			if err != nil {
+				// Containers that never started or have no previous instance have no logs
+				klog.Warningf("Skipping logs of %s/%s container %s: %v", pod.Namespace, pod.Name, container, err)
+				continue
+			}
+
+			header := &tar.Header{
+				Name:    path.Join(pod.Name, container+".log"),
+				Mode:    0644,
+				Size:    size,
+				ModTime: time.Now(),
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				klog.Errorf("Failed to write log archive: %v", err)
+				return
+			}
+			if _, err := io.Copy(tw, spool); err != nil {
+				klog.Errorf("Failed to write log archive: %v", err)
+				return
+			}
+		}
+	}
+}
+
+// spoolPodLogs replaces the content of spool with the logs of one container and rewinds it
+func spoolPodLogs(ctx context.Context, cs *cluster.ClientSet, namespace, podName string, logOptions *corev1.PodLogOptions, opts *logDownloadOptions, spool *os.File) (int64, error) {
+	if err := spool.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	stream, err := cs.K8sClient.ClientSet.CoreV1().Pods(namespace).GetLogs(podName, logOptions).Stream(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	size, err := copyLogLines(spool, stream, opts)
+	if err != nil {
+		return 0, err
+	}
+	_, err = spool.Seek(0, io.SeekStart)
+	return size, err
+}
+
+// copyLogLines copies a log stream, stopping at the first line logged after untilTime.
+// The timestamp prefix requested for the range check is removed unless the user asked for it.
+func copyLogLines(dst io.Writer, src io.Reader, opts *logDownloadOptions) (int64, error) {
+	if opts.until == nil {
+		return io.Copy(dst, src)
+	}
+
+	var written int64
+	reader := bufio.NewReader(src)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if ts, rest, ok := bytes.Cut(line, []byte(" ")); ok {
+				if t, perr := time.Parse(time.RFC3339Nano, string(ts)); perr == nil {
+					// Lines of one container are in order, nothing after this point is in range
+					if t.After(*opts.until) {
+						return written, nil
+					}
+					if !opts.timestamps {
+						line = rest
+					}
+				}
+			}
+			n, werr := dst.Write(line)
+			written += int64(n)
+			if werr != nil {
+				return written, werr
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return written, nil
+			}
+			return written, err
+		}
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -86,10 +88,34 @@ func (h *LogsHandler) HandleLogsWebSocket(c *gin.Context) {
 			logOptions.SinceSeconds = &since
 		}
 
+		filter, err := parseLogFilter(c)
+		if err != nil {
+			_ = sendErrorMessage(ws, err.Error())
+			return
+		}
+
 		labelSelector := c.Query("labelSelector")
+		workload := c.Query("workload")
 		bl := kube.NewBatchLogHandler(ws, cs.K8sClient, logOptions)
+		bl.SetFilter(filter)
 
-		if podName == "_all" && labelSelector != "" {
+		switch {
+		case workload != "":
+			resource, pods, err := resolveWorkload(ctx, cs, namespace, workload)
+			if err != nil {
+				_ = sendErrorMessage(ws, "failed to resolve workload: "+err.Error())
+				return
+			}
+			if !rbac.CanAccess(user, resource, string(common.VerbGet), cs.Name, namespace) {
+				_ = sendErrorMessage(ws, rbac.NoAccess(user.Key(), string(common.VerbGet), resource, namespace, cs.Name))
+				return
+			}
+			if err := h.addPods(ctx, cs, namespace, pods.selector, pods.match, bl); err != nil {
+				_ = sendErrorMessage(ws, "failed to list pods: "+err.Error())
+				return
+			}
+			go h.watchPods(ctx, cs, namespace, metav1.ListOptions{LabelSelector: pods.selector.String()}, pods.match, bl)
+		case podName == "_all" && labelSelector != "":
 			selector, err := metav1.ParseToLabelSelector(labelSelector)
 			if err != nil {
 				_ = sendErrorMessage(ws, "invalid labelSelector parameter: "+err.Error())
@@ -100,23 +126,21 @@ func (h *LogsHandler) HandleLogsWebSocket(c *gin.Context) {
 				_ = sendErrorMessage(ws, "failed to convert labelSelector: "+err.Error())
 				return
 			}
-
-			podList := &corev1.PodList{}
-			var listOpts []client.ListOption
-			listOpts = append(listOpts, client.InNamespace(namespace))
-			listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: labelSelectorOption})
-			if err := cs.K8sClient.List(ctx, podList, listOpts...); err != nil {
+			if err := h.addPods(ctx, cs, namespace, labelSelectorOption, nil, bl); err != nil {
 				_ = sendErrorMessage(ws, "failed to list pods: "+err.Error())
 				return
 			}
-			for _, pod := range podList.Items {
-				if pod.Status.Phase == corev1.PodRunning {
-					bl.AddPod(pod)
-				}
+			go h.watchPods(ctx, cs, namespace, metav1.ListOptions{LabelSelector: labelSelectorOption.String()}, nil, bl)
+		case bl.AllContainers():
+			// The container statuses tell which containers have logs, and the watch picks up containers starting later
+			pod := &corev1.Pod{}
+			if err := cs.K8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, pod); err != nil {
+				_ = sendErrorMessage(ws, "failed to get pod: "+err.Error())
+				return
 			}
-
-			go h.watchPods(ctx, cs, namespace, labelSelectorOption, bl)
-		} else {
+			bl.AddPod(*pod)
+			go h.watchPods(ctx, cs, namespace, metav1.ListOptions{FieldSelector: "metadata.name=" + podName}, nil, bl)
+		default:
 			bl.AddPod(corev1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      podName,
@@ -129,11 +153,49 @@ func (h *LogsHandler) HandleLogsWebSocket(c *gin.Context) {
 	}).ServeHTTP(c.Writer, c.Request)
 }
 
-func (h *LogsHandler) watchPods(ctx context.Context, cs *cluster.ClientSet, namespace string, labelSelector labels.Selector, bl *kube.BatchLogHandler) {
-	listOptions := metav1.ListOptions{
-		LabelSelector: labelSelector.String(),
+// parseLogFilter reads the initial log filter from include, exclude, caseSensitive, before, after
+// and the repeatable field parameters. The client can replace it later with a filter message.
+func parseLogFilter(c *gin.Context) (*kube.LogFilter, error) {
+	opts := kube.LogFilterOptions{
+		Include:       c.Query("include"),
+		Exclude:       c.Query("exclude"),
+		CaseSensitive: c.Query("caseSensitive") == "true",
+		Fields:        c.QueryArray("field"),
+	}
+	for param, target := range map[string]*int{"before": &opts.Before, "after": &opts.After} {
+		if v := c.Query(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid %s parameter", param)
+			}
+			*target = n
+		}
 	}
+	return kube.NewLogFilter(opts)
+}
 
+// addPods starts streaming the logs of the pods matching the selector, match further narrows them down if set
+func (h *LogsHandler) addPods(ctx context.Context, cs *cluster.ClientSet, namespace string, selector labels.Selector, match func(*corev1.Pod) bool, bl *kube.BatchLogHandler) error {
+	podList := &corev1.PodList{}
+	var listOpts []client.ListOption
+	listOpts = append(listOpts, client.InNamespace(namespace))
+	listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
+	if err := cs.K8sClient.List(ctx, podList, listOpts...); err != nil {
+		return err
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if match != nil && !match(pod) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodRunning || bl.AllContainers() {
+			bl.AddPod(*pod)
+		}
+	}
+	return nil
+}
+
+func (h *LogsHandler) watchPods(ctx context.Context, cs *cluster.ClientSet, namespace string, listOptions metav1.ListOptions, match func(*corev1.Pod) bool, bl *kube.BatchLogHandler) {
 	watchInterface, err := cs.K8sClient.ClientSet.CoreV1().Pods(namespace).Watch(ctx, listOptions)
 	if err != nil {
 		return
@@ -153,12 +215,17 @@ func (h *LogsHandler) watchPods(ctx context.Context, cs *cluster.ClientSet, name
 			if !ok {
 				continue
 			}
+			if match != nil && !match(pod) {
+				continue
+			}
 
 			klog.Infof("Pod %s in namespace %s is %s, event Type: %s", pod.Name, pod.Namespace, pod.Status.Phase, event.Type)
 
 			switch event.Type {
 			case watch.Added, watch.Modified:
-				if pod.Status.Phase == corev1.PodRunning {
+				// With all containers, init containers of pending pods have logs and the
+				// streams of finished containers end by themselves
+				if pod.Status.Phase == corev1.PodRunning || bl.AllContainers() {
 					bl.AddPod(*pod)
 				} else {
 					bl.RemovePod(*pod)
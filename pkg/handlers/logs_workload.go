@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// workloadPods matches the pods of a workload through their controller owner references.
+// Deployments own their pods through ReplicaSets and CronJobs through Jobs, these
+// intermediate owners are looked up once and remembered.
+type workloadPods struct {
+	cs           *cluster.ClientSet
+	uid          types.UID
+	selector     labels.Selector
+	intermediate string // kind of the owner between the workload and its pods
+
+	mu     sync.Mutex
+	owners map[types.UID]bool
+}
+
+// resolveWorkload parses a workload reference like deployments/foo and looks the workload up
+func resolveWorkload(ctx context.Context, cs *cluster.ClientSet, namespace, ref string) (string, *workloadPods, error) {
+	resource, name, ok := strings.Cut(ref, "/")
+	if !ok || name == "" {
+		return "", nil, fmt.Errorf("invalid workload %q, expected <resource>/<name>", ref)
+	}
+
+	w := &workloadPods{
+		cs:       cs,
+		selector: labels.Everything(),
+		owners:   make(map[types.UID]bool),
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	var obj client.Object
+	var selector *metav1.LabelSelector
+	switch resource {
+	case "deployments":
+		deployment := &appsv1.Deployment{}
+		obj = deployment
+		if err := cs.K8sClient.Get(ctx, key, deployment); err != nil {
+			return resource, nil, err
+		}
+		selector = deployment.Spec.Selector
+		w.intermediate = "ReplicaSet"
+	case "replicasets":
+		replicaSet := &appsv1.ReplicaSet{}
+		obj = replicaSet
+		if err := cs.K8sClient.Get(ctx, key, replicaSet); err != nil {
+			return resource, nil, err
+		}
+		selector = replicaSet.Spec.Selector
+	case "statefulsets":
+		statefulSet := &appsv1.StatefulSet{}
+		obj = statefulSet
+		if err := cs.K8sClient.Get(ctx, key, statefulSet); err != nil {
+			return resource, nil, err
+		}
+		selector = statefulSet.Spec.Selector
+	case "daemonsets":
+		daemonSet := &appsv1.DaemonSet{}
+		obj = daemonSet
+		if err := cs.K8sClient.Get(ctx, key, daemonSet); err != nil {
+			return resource, nil, err
+		}
+		selector = daemonSet.Spec.Selector
+	case "jobs":
+		job := &batchv1.Job{}
+		obj = job
+		if err := cs.K8sClient.Get(ctx, key, job); err != nil {
+			return resource, nil, err
+		}
+		selector = job.Spec.Selector
+	case "cronjobs":
+		// The pods of a CronJob share no label, so all pods of the namespace are checked
+		cronJob := &batchv1.CronJob{}
+		obj = cronJob
+		if err := cs.K8sClient.Get(ctx, key, cronJob); err != nil {
+			return resource, nil, err
+		}
+		w.intermediate = "Job"
+	default:
+		return resource, nil, fmt.Errorf("unsupported workload resource %q", resource)
+	}
+	w.uid = obj.GetUID()
+
+	if selector != nil {
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return resource, nil, err
+		}
+		w.selector = s
+	}
+	return resource, w, nil
+}
+
+// match reports whether the workload controls the pod, directly or through an intermediate owner
+func (w *workloadPods) match(pod *corev1.Pod) bool {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return false
+	}
+	if ref.UID == w.uid {
+		return true
+	}
+	if ref.Kind != w.intermediate {
+		return false
+	}
+
+	w.mu.Lock()
+	owned, known := w.owners[ref.UID]
+	w.mu.Unlock()
+	if known {
+		return owned
+	}
+
+	owner, err := w.getOwner(pod.Namespace, ref.Name)
+	if err != nil || owner.GetUID() != ref.UID {
+		// Not cached yet or gone, the next event of the pod tries again
+		return false
+	}
+	controller := metav1.GetControllerOf(owner)
+	owned = controller != nil && controller.UID == w.uid
+
+	w.mu.Lock()
+	w.owners[ref.UID] = owned
+	w.mu.Unlock()
+	return owned
+}
+
+// getOwner fetches an intermediate owner, falling back to the API server when
+// the informer cache has not seen a just created ReplicaSet or Job yet
+func (w *workloadPods) getOwner(namespace, name string) (client.Object, error) {
+	var owner client.Object = &appsv1.ReplicaSet{}
+	if w.intermediate == "Job" {
+		owner = &batchv1.Job{}
+	}
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if err := w.cs.K8sClient.Get(ctx, key, owner); err != nil {
+		if err := w.cs.K8sClient.WatchClient.Get(ctx, key, owner); err != nil {
+			return nil, err
+		}
+	}
+	return owner, nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,8 +17,11 @@ import (
 	"github.com/xhilmi/kubedash/pkg/model"
 	"github.com/xhilmi/kubedash/pkg/rbac"
 	"github.com/xhilmi/kubedash/pkg/utils"
+	"gorm.io/gorm"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 )
@@ -60,10 +64,26 @@ func (h *NodeTerminalHandler) HandleNodeTerminalWebSocket(c *gin.Context) {
 			h.sendErrorMessage(conn, fmt.Sprintf("Node %s not found", nodeName))
 			return
 		}
+		config, err := loadNodeTerminalConfig(cs.Name)
+		if err != nil {
+			klog.Errorf("Failed to load node terminal config: %v", err)
+			h.sendErrorMessage(conn, err.Error())
+			return
+		}
+		if !labels.SelectorFromSet(config.NodeSelector).Matches(labels.Set(node.Labels)) {
+			h.sendErrorMessage(conn, fmt.Sprintf("Node %s does not match the node terminal node selector %s", nodeName, labels.Set(config.NodeSelector)))
+			return
+		}
+		idleTimeout, err := config.IdleTimeoutDuration()
+		if err != nil {
+			h.sendErrorMessage(conn, err.Error())
+			return
+		}
+
 		ctx, cancel := context.WithCancel(c.Request.Context())
 		defer cancel()
 
-		nodeAgentName, err := h.createNodeAgent(ctx, cs, nodeName)
+		nodeAgentName, err := h.createNodeAgent(ctx, cs, config, nodeName)
 		if err != nil {
 			log.Printf("Failed to create node agent pod: %v", err)
 			h.sendErrorMessage(conn, fmt.Sprintf("Failed to create node agent pod: %v", err))
@@ -73,25 +93,85 @@ func (h *NodeTerminalHandler) HandleNodeTerminalWebSocket(c *gin.Context) {
 		// Ensure cleanup of the node agent pod
 		defer func() {
 			klog.Infof("Cleaning up node agent pod %s", nodeAgentName)
-			if err := h.cleanupNodeAgentPod(cs, nodeAgentName); err != nil {
+			if err := h.cleanupNodeAgentPod(cs, config.Namespace, nodeAgentName); err != nil {
 				log.Printf("Failed to cleanup node agent pod %s: %v", nodeAgentName, err)
 			}
 		}()
 
-		if err := h.waitForPodReady(ctx, cs, conn, nodeAgentName); err != nil {
+		if err := h.waitForPodReady(ctx, cs, conn, config.Namespace, nodeAgentName); err != nil {
 			log.Printf("Failed to wait for pod ready: %v", err)
 			h.sendErrorMessage(conn, fmt.Sprintf("Failed to wait for pod ready: %v", err))
 			return
 		}
 
-		session := kube.NewTerminalSession(cs.K8sClient, conn, "kube-system", nodeAgentName, common.NodeTerminalPodName)
+		go h.keepNodeAgentAlive(ctx, cs, config.Namespace, nodeAgentName)
+
+		session := kube.NewTerminalSession(cs.K8sClient, conn, config.Namespace, nodeAgentName, common.NodeTerminalPodName)
+		session.SetIdleTimeout(idleTimeout)
+		recording, err := startTerminalRecording(cs.Name, user, &model.TerminalRecording{
+			Kind:      model.TerminalRecordingKindNode,
+			Namespace: config.Namespace,
+			PodName:   nodeAgentName,
+			Container: common.NodeTerminalPodName,
+			NodeName:  nodeName,
+		})
+		if err != nil {
+			klog.Errorf("Failed to start terminal recording: %v", err)
+			h.sendErrorMessage(conn, err.Error())
+			return
+		}
+		if recording != nil {
+			defer recording.finish()
+			session.SetRecorder(recording.recorder)
+		}
 		if err := session.Start(ctx, "attach"); err != nil {
 			klog.Errorf("Terminal session error: %v", err)
 		}
 	}).ServeHTTP(c.Writer, c.Request)
 }
 
-func (h *NodeTerminalHandler) createNodeAgent(ctx context.Context, cs *cluster.ClientSet, nodeName string) (string, error) {
+// loadNodeTerminalConfig returns the node terminal settings of a cluster, clusters
+// configured outside the database use the defaults
+func loadNodeTerminalConfig(clusterName string) (common.NodeTerminalConfig, error) {
+	clusterConfig, err := model.GetClusterByName(clusterName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.DefaultNodeTerminalConfig(), nil
+		}
+		return common.NodeTerminalConfig{}, fmt.Errorf("failed to read cluster settings: %w", err)
+	}
+	return clusterConfig.NodeTerminal()
+}
+
+// nodeAgentResources builds the resource requirements of the agent container, empty values are left unset
+func nodeAgentResources(config common.NodeTerminalConfig) (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}
+	for _, r := range []struct {
+		list  corev1.ResourceList
+		name  corev1.ResourceName
+		value string
+	}{
+		{requirements.Requests, corev1.ResourceCPU, config.CPURequest},
+		{requirements.Requests, corev1.ResourceMemory, config.MemoryRequest},
+		{requirements.Limits, corev1.ResourceCPU, config.CPULimit},
+		{requirements.Limits, corev1.ResourceMemory, config.MemoryLimit},
+	} {
+		if r.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(r.value)
+		if err != nil {
+			return requirements, fmt.Errorf("invalid node terminal %s %q: %w", r.name, r.value, err)
+		}
+		r.list[r.name] = quantity
+	}
+	return requirements, nil
+}
+
+func (h *NodeTerminalHandler) createNodeAgent(ctx context.Context, cs *cluster.ClientSet, config common.NodeTerminalConfig, nodeName string) (string, error) {
 	truncateNodeName := nodeName
 	if len(nodeName)+len(common.NodeTerminalPodName)+5 > 63 {
 		maxLength := 63 - len(common.NodeTerminalPodName) - 5
@@ -99,21 +179,36 @@ func (h *NodeTerminalHandler) createNodeAgent(ctx context.Context, cs *cluster.C
 	}
 	podName := fmt.Sprintf("%s-%s-%s", common.NodeTerminalPodName, truncateNodeName, utils.RandomString(5))
 
-	// Define the kite node agent pod spec
+	resources, err := nodeAgentResources(config)
+	if err != nil {
+		return "", err
+	}
+	imagePullSecrets := make([]corev1.LocalObjectReference, 0, len(config.ImagePullSecrets))
+	for _, name := range config.ImagePullSecrets {
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+
+	// Define the kite node agent pod spec, the labels let the reaper find agents left behind
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      podName,
-			Namespace: "kube-system",
+			Namespace: config.Namespace,
 			Labels: map[string]string{
-				"app": podName,
+				"app":                            podName,
+				common.NodeTerminalAgentLabel:    "true",
+				common.NodeTerminalInstanceLabel: common.InstanceID,
+			},
+			Annotations: map[string]string{
+				common.NodeTerminalLastActiveAnnotation: time.Now().UTC().Format(time.RFC3339),
 			},
 		},
 		Spec: corev1.PodSpec{
-			NodeName:      nodeName,
-			HostNetwork:   true,
-			HostPID:       true,
-			HostIPC:       true,
-			RestartPolicy: corev1.RestartPolicyNever,
+			NodeName:         nodeName,
+			ImagePullSecrets: imagePullSecrets,
+			HostNetwork:      true,
+			HostPID:          true,
+			HostIPC:          true,
+			RestartPolicy:    corev1.RestartPolicyNever,
 			Tolerations: []corev1.Toleration{
 				{
 					Operator: corev1.TolerationOpExists,
@@ -132,7 +227,8 @@ func (h *NodeTerminalHandler) createNodeAgent(ctx context.Context, cs *cluster.C
 			Containers: []corev1.Container{
 				{
 					Name:      common.NodeTerminalPodName,
-					Image:     common.NodeTerminalImage,
+					Image:     config.Image,
+					Resources: resources,
 					Stdin:     true,
 					StdinOnce: true,
 					TTY:       true,
@@ -152,7 +248,7 @@ func (h *NodeTerminalHandler) createNodeAgent(ctx context.Context, cs *cluster.C
 	}
 
 	object := &corev1.Pod{}
-	namespacedName := types.NamespacedName{Name: podName, Namespace: "kube-system"}
+	namespacedName := types.NamespacedName{Name: podName, Namespace: config.Namespace}
 	if err := cs.K8sClient.Get(ctx, namespacedName, object); err == nil {
 		if utils.IsPodErrorOrSuccess(object) {
 			if err := cs.K8sClient.Delete(ctx, object); err != nil {
@@ -164,8 +260,7 @@ func (h *NodeTerminalHandler) createNodeAgent(ctx context.Context, cs *cluster.C
 	}
 
 	// Create the pod
-	err := cs.K8sClient.Create(ctx, pod)
-	if err != nil {
+	if err := cs.K8sClient.Create(ctx, pod); err != nil {
 		return "", fmt.Errorf("failed to create kite node agent pod: %w", err)
 	}
 
@@ -173,7 +268,7 @@ func (h *NodeTerminalHandler) createNodeAgent(ctx context.Context, cs *cluster.C
 }
 
 // waitForPodReady waits for the kite node agent pod to be ready
-func (h *NodeTerminalHandler) waitForPodReady(ctx context.Context, cs *cluster.ClientSet, conn *websocket.Conn, podName string) error {
+func (h *NodeTerminalHandler) waitForPodReady(ctx context.Context, cs *cluster.ClientSet, conn *websocket.Conn, namespace, podName string) error {
 	timeout := time.After(60 * time.Second)
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
@@ -190,7 +285,7 @@ func (h *NodeTerminalHandler) waitForPodReady(ctx context.Context, cs *cluster.C
 			h.sendErrorMessage(conn, utils.GetPodErrorMessage(pod))
 			return fmt.Errorf("timeout waiting for pod %s to be ready", podName)
 		case <-ticker.C:
-			pod, err = cs.K8sClient.ClientSet.CoreV1().Pods("kube-system").Get(
+			pod, err = cs.K8sClient.ClientSet.CoreV1().Pods(namespace).Get(
 				context.TODO(),
 				podName,
 				metav1.GetOptions{},
@@ -207,8 +302,26 @@ func (h *NodeTerminalHandler) waitForPodReady(ctx context.Context, cs *cluster.C
 	}
 }
 
-func (h *NodeTerminalHandler) cleanupNodeAgentPod(cs *cluster.ClientSet, podName string) error {
-	return cs.K8sClient.ClientSet.CoreV1().Pods("kube-system").Delete(
+// keepNodeAgentAlive refreshes the last active annotation of the agent pod while the session runs,
+// so the reaper can tell agents of a running session from those left by a stopped instance
+func (h *NodeTerminalHandler) keepNodeAgentAlive(ctx context.Context, cs *cluster.ClientSet, namespace, podName string) {
+	ticker := time.NewTicker(common.NodeTerminalHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, common.NodeTerminalLastActiveAnnotation, time.Now().UTC().Format(time.RFC3339))
+			if _, err := cs.K8sClient.ClientSet.CoreV1().Pods(namespace).Patch(ctx, podName, types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil && ctx.Err() == nil {
+				klog.Warningf("Failed to refresh node agent pod %s/%s: %v", namespace, podName, err)
+			}
+		}
+	}
+}
+
+func (h *NodeTerminalHandler) cleanupNodeAgentPod(cs *cluster.ClientSet, namespace, podName string) error {
+	return cs.K8sClient.ClientSet.CoreV1().Pods(namespace).Delete(
 		context.TODO(),
 		podName,
 		metav1.DeleteOptions{},
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	"github.com/xhilmi/kubedash/pkg/utils"
+	"golang.org/x/net/websocket"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type PortForwardHandler struct {
+}
+
+func NewPortForwardHandler() *PortForwardHandler {
+	return &PortForwardHandler{}
+}
+
+// HandlePortForwardWebSocket forwards one TCP connection to a pod or service port.
+// Binary frames carry the raw TCP bytes in both directions, text frames carry JSON
+// status messages: "connected" once the stream is open and "error" before it closes.
+func (h *PortForwardHandler) HandlePortForwardWebSocket(c *gin.Context) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
+
+	namespace := c.Param("namespace")
+	kind := c.Param("kind")
+	name := c.Param("name")
+	port := c.Query("port")
+	if kind != "pods" && kind != "services" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid kind, must be 'pods' or 'services'"})
+		return
+	}
+	if port == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "port is required"})
+		return
+	}
+
+	websocket.Handler(func(ws *websocket.Conn) {
+		defer func() {
+			_ = ws.Close()
+		}()
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		if !rbac.CanAccess(user, "pods", string(common.VerbPortForward), cs.Name, namespace) {
+			h.sendErrorMessage(ws, rbac.NoAccess(user.Key(), string(common.VerbPortForward), "pods", namespace, cs.Name))
+			return
+		}
+		if kind == "services" && !rbac.CanAccess(user, "services", string(common.VerbGet), cs.Name, namespace) {
+			h.sendErrorMessage(ws, rbac.NoAccess(user.Key(), string(common.VerbGet), "services", namespace, cs.Name))
+			return
+		}
+
+		var pod *corev1.Pod
+		var targetPort int32
+		var err error
+		if kind == "services" {
+			pod, targetPort, err = resolveServicePod(ctx, cs, namespace, name, port)
+		} else {
+			pod, targetPort, err = resolvePodPort(ctx, cs, namespace, name, port)
+		}
+		if err != nil {
+			h.sendErrorMessage(ws, err.Error())
+			return
+		}
+
+		klog.Infof("User %s forwarding to %s/%s:%d in cluster %s", user.Key(), namespace, pod.Name, targetPort, cs.Name)
+		h.sendMessage(ws, "connected", fmt.Sprintf("%s:%d", pod.Name, targetPort))
+		ws.PayloadType = websocket.BinaryFrame
+		if err := kube.PortForward(ctx, cs.K8sClient, namespace, pod.Name, targetPort, ws); err != nil {
+			klog.Warningf("Port forward to %s/%s:%d failed: %v", namespace, pod.Name, targetPort, err)
+			h.sendErrorMessage(ws, err.Error())
+		}
+	}).ServeHTTP(c.Writer, c.Request)
+}
+
+// resolvePodPort gets a running pod and resolves a port number or container port name
+func resolvePodPort(ctx context.Context, cs *cluster.ClientSet, namespace, name, port string) (*corev1.Pod, int32, error) {
+	pod := &corev1.Pod{}
+	if err := cs.K8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod); err != nil {
+		return nil, 0, fmt.Errorf("failed to get pod %s: %w", name, err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, 0, fmt.Errorf("pod %s is not running, phase: %s", name, pod.Status.Phase)
+	}
+	targetPort, err := containerPort(pod, intstr.Parse(port))
+	if err != nil {
+		return nil, 0, err
+	}
+	return pod, targetPort, nil
+}
+
+// resolveServicePod picks a ready pod behind a service and maps the service port, given
+// by number or name, to the target port of that pod
+func resolveServicePod(ctx context.Context, cs *cluster.ClientSet, namespace, name, port string) (*corev1.Pod, int32, error) {
+	svc := &corev1.Service{}
+	if err := cs.K8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, svc); err != nil {
+		return nil, 0, fmt.Errorf("failed to get service %s: %w", name, err)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return nil, 0, fmt.Errorf("service %s has no selector", name)
+	}
+
+	var servicePort *corev1.ServicePort
+	for i := range svc.Spec.Ports {
+		p := &svc.Spec.Ports[i]
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			servicePort = p
+			break
+		}
+	}
+	if servicePort == nil {
+		return nil, 0, fmt.Errorf("service %s has no port %s", name, port)
+	}
+
+	var pods corev1.PodList
+	if err := cs.K8sClient.List(ctx, &pods, client.InNamespace(namespace),
+		client.MatchingLabelsSelector{Selector: labels.SelectorFromSet(svc.Spec.Selector)}); err != nil {
+		return nil, 0, fmt.Errorf("failed to list pods of service %s: %w", name, err)
+	}
+	// Prefer the oldest ready pod, like kubectl port-forward
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || !utils.IsPodReady(pod) {
+			continue
+		}
+		targetPort := servicePort.TargetPort
+		if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+			targetPort = intstr.FromInt32(servicePort.Port)
+		}
+		p, err := containerPort(pod, targetPort)
+		if err != nil {
+			// Pods of a rollout may not have the named port yet
+			continue
+		}
+		return pod, p, nil
+	}
+	return nil, 0, fmt.Errorf("service %s has no ready pod for port %s", name, port)
+}
+
+// containerPort resolves a port number or the name of a container port of the pod
+func containerPort(pod *corev1.Pod, port intstr.IntOrString) (int32, error) {
+	if port.Type == intstr.Int {
+		if port.IntVal <= 0 || port.IntVal > 65535 {
+			return 0, fmt.Errorf("invalid port %d", port.IntVal)
+		}
+		return port.IntVal, nil
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, p := range container.Ports {
+			if p.Name == port.StrVal && p.Protocol != corev1.ProtocolUDP {
+				return p.ContainerPort, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("pod %s has no container port named %s", pod.Name, port.StrVal)
+}
+
+// sendMessage sends a status message through WebSocket as a text frame
+func (h *PortForwardHandler) sendMessage(conn *websocket.Conn, msgType, message string) {
+	msg := map[string]interface{}{
+		"type": msgType,
+		"data": message,
+	}
+	if err := websocket.JSON.Send(conn, msg); err != nil {
+		klog.Errorf("Failed to send message: %v", err)
+	}
+}
+
+// sendErrorMessage sends an error message through WebSocket
+func (h *PortForwardHandler) sendErrorMessage(conn *websocket.Conn, message string) {
+	h.sendMessage(conn, "error", message)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
 	"github.com/xhilmi/kubedash/pkg/kube"
 	"github.com/xhilmi/kubedash/pkg/model"
 	"github.com/xhilmi/kubedash/pkg/rbac"
@@ -17,7 +18,18 @@ func NewProxyHandler() *ProxyHandler {
 }
 
 func (h *ProxyHandler) RegisterRoutes(rg *gin.RouterGroup) {
-	rg.GET("/namespaces/:namespace/:kind/:name/proxy/*path", h.HandleProxy)
+	rg.Any("/namespaces/:namespace/:kind/:name/proxy/*path", h.HandleProxy)
+}
+
+// proxyVerbs maps request methods to RBAC verbs like the API server does for the proxy subresource
+var proxyVerbs = map[string]common.Verb{
+	http.MethodGet:     common.VerbGet,
+	http.MethodHead:    common.VerbGet,
+	http.MethodOptions: common.VerbGet,
+	http.MethodPost:    common.VerbCreate,
+	http.MethodPut:     common.VerbUpdate,
+	http.MethodPatch:   common.VerbPatch,
+	http.MethodDelete:  common.VerbDelete,
 }
 
 func (h *ProxyHandler) HandleProxy(c *gin.Context) {
@@ -30,8 +42,13 @@ func (h *ProxyHandler) HandleProxy(c *gin.Context) {
 	}
 	name := c.Param("name")
 	namespace := c.Param("namespace")
-	if !rbac.CanAccess(user, kind, "get", cs.Name, namespace) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	verb, ok := proxyVerbs[c.Request.Method]
+	if !ok {
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "method not allowed"})
+		return
+	}
+	if !rbac.CanAccess(user, kind, string(verb), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{"error": rbac.NoAccess(user.Key(), string(verb), kind, namespace, cs.Name)})
 		return
 	}
 	kube.HandleProxy(c, cs.K8sClient, kind, namespace, name, c.Param("path"))
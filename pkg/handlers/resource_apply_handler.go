@@ -1,22 +1,34 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"strings"
+	"regexp"
+	"sort"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xhilmi/kubedash/pkg/cluster"
 	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
 	"github.com/xhilmi/kubedash/pkg/model"
 	"github.com/xhilmi/kubedash/pkg/rbac"
+	"github.com/xhilmi/kubedash/pkg/utils"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	syaml "sigs.k8s.io/yaml"
 )
 
+// FieldManager is the field manager Kite uses for server-side apply
+const FieldManager = "kite"
+
 type ResourceApplyHandler struct {
 }
 
@@ -28,96 +40,327 @@ type ApplyResourceRequest struct {
 	YAML string `json:"yaml" binding:"required"`
 }
 
-// ApplyResource applies a YAML resource to the cluster
-func (h *ResourceApplyHandler) ApplyResource(c *gin.Context) {
-	cs := c.MustGet("cluster").(*cluster.ClientSet)
-	user := c.MustGet("user").(model.User)
+// ApplyConflict describes a field owned by another field manager that blocked the apply
+type ApplyConflict struct {
+	Manager    string `json:"manager"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Field      string `json:"field"`
+	Message    string `json:"message"`
+}
+
+// ApplyResult is the outcome of applying a single object of the submitted manifest
+type ApplyResult struct {
+	APIVersion string          `json:"apiVersion"`
+	Kind       string          `json:"kind"`
+	Name       string          `json:"name"`
+	Namespace  string          `json:"namespace,omitempty"`
+	Status     string          `json:"status"` // created, configured, unchanged or failed
+	DryRun     bool            `json:"dryRun,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Conflicts  []ApplyConflict `json:"conflicts,omitempty"`
+	HistoryID  uint            `json:"historyId,omitempty"`
+	Diff       string          `json:"diff,omitempty"`
+	Object     map[string]any  `json:"object,omitempty"`
 
+	httpStatus int
+}
+
+// conflictMessagePattern matches messages such as: conflict with "kubectl-client-side-apply" using apps/v1
+var conflictMessagePattern = regexp.MustCompile(`conflict with "([^"]*)"(?:.* using (\S+))?`)
+
+// applyConflicts extracts the field manager conflicts from a server-side apply error
+func applyConflicts(err error) []ApplyConflict {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return nil
+	}
+	var conflicts []ApplyConflict
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflict := ApplyConflict{Field: cause.Field, Message: cause.Message}
+		if m := conflictMessagePattern.FindStringSubmatch(cause.Message); m != nil {
+			conflict.Manager = m[1]
+			conflict.APIVersion = m[2]
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// toYAML renders an object for diffs and history without server managed noise
+func toYAML(obj *unstructured.Unstructured) string {
+	o := obj.DeepCopy()
+	o.SetManagedFields(nil)
+	if anno := o.GetAnnotations(); anno != nil {
+		delete(anno, common.KubectlAnnotation)
+		o.SetAnnotations(anno)
+	}
+	b, err := syaml.Marshal(o.Object)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// decodeManifest splits a multi-document YAML or JSON stream into objects, expanding List kinds
+func decodeManifest(manifest string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+	var objs []*unstructured.Unstructured
+	for i := 1; ; i++ {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if len(obj.Object) == 0 {
+			// empty document, e.g. a trailing ---
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("document %d: apiVersion and kind are required", i)
+		}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %w", i, err)
+			}
+			for j := range list.Items {
+				objs = append(objs, &list.Items[j])
+			}
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// applyOrder returns the position of an object in the apply order:
+// namespaces and CRDs go first so the objects that depend on them can be created
+func applyOrder(obj *unstructured.Unstructured) int {
+	gvk := obj.GroupVersionKind()
+	switch {
+	case gvk.Group == "" && gvk.Kind == "Namespace":
+		return 0
+	case gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// ApplyResource applies one or more YAML documents to the cluster with server-side apply.
+// ?force=true takes ownership of fields managed by others, ?dryRun=true returns
+// the objects the server would persist together with a diff against the live objects.
+func (h *ResourceApplyHandler) ApplyResource(c *gin.Context) {
 	var req ApplyResourceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	force := c.Query("force") == "true"
+	dryRun := c.Query("dryRun") == "true"
 
-	// Decode YAML into unstructured object
-	decodeUniversal := yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
-	obj := &unstructured.Unstructured{}
-
-	_, _, err := decodeUniversal.Decode([]byte(req.YAML), nil, obj)
+	objs, err := decodeManifest(req.YAML)
 	if err != nil {
 		klog.Errorf("Failed to decode YAML: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid YAML format: " + err.Error()})
 		return
 	}
+	if len(objs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No objects found in YAML"})
+		return
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return applyOrder(objs[i]) < applyOrder(objs[j])
+	})
+
+	results := make([]ApplyResult, 0, len(objs))
+	failed := 0
+	var firstFailure *ApplyResult
+	for _, obj := range objs {
+		result := h.applyObject(c, obj, force, dryRun)
+		results = append(results, result)
+		if result.Status == "failed" {
+			failed++
+			if firstFailure == nil {
+				firstFailure = &results[len(results)-1]
+			}
+		}
+	}
 
-	resource := strings.ToLower(obj.GetKind()) + "s"
-	if !rbac.CanAccess(user, resource, "create", cs.Name, obj.GetNamespace()) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": rbac.NoAccess(user.Key(), string(common.VerbCreate), resource, obj.GetNamespace(), cs.Name)})
+	first := results[0]
+	response := gin.H{
+		"kind":      first.Kind,
+		"name":      first.Name,
+		"namespace": first.Namespace,
+		"results":   results,
+	}
+	if dryRun {
+		response["dryRun"] = true
+		if len(results) == 1 {
+			response["object"] = first.Object
+			response["diff"] = first.Diff
+		}
+	}
+
+	if firstFailure != nil {
+		response["error"] = firstFailure.Error
+		if len(results) > 1 {
+			response["error"] = fmt.Sprintf("%d of %d objects failed to apply, first error: %s", failed, len(results), firstFailure.Error)
+		}
+		if len(firstFailure.Conflicts) > 0 {
+			response["conflicts"] = firstFailure.Conflicts
+		}
+		c.JSON(firstFailure.httpStatus, response)
 		return
 	}
 
+	switch {
+	case dryRun:
+		response["message"] = "Dry run succeeded, nothing was persisted"
+	case len(results) == 1:
+		response["message"] = "Resource applied successfully"
+	default:
+		response["message"] = fmt.Sprintf("%d resources applied successfully", len(results))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// applyObject checks RBAC for a single object, applies it and records its history
+func (h *ResourceApplyHandler) applyObject(c *gin.Context, obj *unstructured.Unstructured, force, dryRun bool) ApplyResult {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
 	ctx := c.Request.Context()
 
-	existingObj := &unstructured.Unstructured{}
-	existingObj.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
-	existingObj.SetName(obj.GetName())
-	existingObj.SetNamespace(obj.GetNamespace())
+	result := ApplyResult{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		DryRun:     dryRun,
+	}
+	fail := func(status int, err error) ApplyResult {
+		result.Status = "failed"
+		result.Error = err.Error()
+		result.httpStatus = status
+		return result
+	}
 
-	err = cs.K8sClient.Get(ctx, client.ObjectKey{
+	// Objects copied from the cluster carry server populated metadata that apply rejects
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+
+	// Resolve the real plural name and scope of the kind, CRDs applied earlier in the
+	// same manifest become known through a discovery refresh
+	mapping, err := cs.K8sClient.Mapper.MappingFor(obj.GroupVersionKind())
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return fail(http.StatusBadRequest, fmt.Errorf("unknown kind %s: %w", obj.GroupVersionKind().String(), err))
+		}
+		return fail(http.StatusInternalServerError, fmt.Errorf("failed to resolve kind: %w", err))
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace("default")
+		}
+	} else {
+		obj.SetNamespace("")
+	}
+	result.Namespace = obj.GetNamespace()
+
+	existingObj := &unstructured.Unstructured{}
+	existingObj.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := cs.K8sClient.Get(ctx, client.ObjectKey{
 		Name:      obj.GetName(),
 		Namespace: obj.GetNamespace(),
-	}, existingObj)
+	}, existingObj); err != nil && !apierrors.IsNotFound(err) {
+		klog.Errorf("Failed to get resource: %v", err)
+		return fail(http.StatusInternalServerError, fmt.Errorf("failed to get resource: %w", err))
+	}
 
-	defer func() {
-		previousYAML := []byte{}
-		if existingObj.GetResourceVersion() != "" {
-			existingObj.SetManagedFields(nil)
-			previousYAML, _ = syaml.Marshal(existingObj)
-		}
+	// Creating needs the create verb, changing an existing object needs patch
+	resource := kube.ResourceName(mapping.Resource)
+	verb := common.VerbCreate
+	liveYAML := ""
+	if existingObj.GetResourceVersion() != "" {
+		verb = common.VerbPatch
+		liveYAML = toYAML(existingObj)
+	}
+	if !rbac.CanAccess(user, resource, string(verb), cs.Name, obj.GetNamespace()) {
+		return fail(http.StatusForbidden, errors.New(rbac.NoAccess(user.Key(), string(verb), resource, obj.GetNamespace(), cs.Name)))
+	}
+
+	opts := []client.ApplyOption{client.FieldOwner(FieldManager)}
+	if force {
+		opts = append(opts, client.ForceOwnership)
+	}
+	if dryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	applied := obj.DeepCopy()
+	err = cs.K8sClient.Apply(ctx, client.ApplyConfigurationFromUnstructured(applied), opts...)
+
+	if !dryRun {
 		errMessage := ""
 		if err != nil {
 			errMessage = err.Error()
 		}
-		model.DB.Create(&model.ResourceHistory{
+		history := model.ResourceHistory{
 			ClusterName:   cs.Name,
 			ResourceType:  resource,
 			ResourceName:  obj.GetName(),
 			Namespace:     obj.GetNamespace(),
 			OperationType: "apply",
-			ResourceYAML:  req.YAML,
-			PreviousYAML:  string(previousYAML),
+			ResourceYAML:  toYAML(obj),
+			PreviousYAML:  liveYAML,
 			OperatorID:    user.ID,
 			Success:       err == nil,
 			ErrorMessage:  errMessage,
-		})
-	}()
+		}
+		if dbErr := model.DB.Create(&history).Error; dbErr != nil {
+			klog.Errorf("Failed to create resource history: %v", dbErr)
+		} else {
+			result.HistoryID = history.ID
+		}
+	}
 
-	switch {
-	case apierrors.IsNotFound(err):
-		if err := cs.K8sClient.Create(ctx, obj); err != nil {
-			klog.Errorf("Failed to create resource: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create resource: " + err.Error()})
-			return
-		}
-	case err == nil:
-		obj.SetResourceVersion(existingObj.GetResourceVersion())
-		if err := cs.K8sClient.Update(ctx, obj); err != nil {
-			klog.Errorf("Failed to update resource: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update resource: " + err.Error()})
-			return
+	if err != nil {
+		klog.Errorf("Failed to apply resource %s/%s: %v", obj.GetKind(), obj.GetName(), err)
+		if conflicts := applyConflicts(err); len(conflicts) > 0 {
+			result.Conflicts = conflicts
+			return fail(http.StatusConflict, fmt.Errorf("apply conflicts with fields owned by other managers, retry with force to take ownership"))
 		}
-	default:
-		klog.Errorf("Failed to get resource: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get resource: " + err.Error()})
-		return
+		status := http.StatusInternalServerError
+		if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+			status = http.StatusBadRequest
+		}
+		return fail(status, fmt.Errorf("failed to apply resource: %w", err))
 	}
 
-	klog.Infof("Successfully applied resource: %s/%s", obj.GetKind(), obj.GetName())
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Resource applied successfully",
-		"kind":      obj.GetKind(),
-		"name":      obj.GetName(),
-		"namespace": obj.GetNamespace(),
-	})
+	// A dry run keeps the live resourceVersion, so compare the rendered objects instead
+	appliedYAML := toYAML(applied)
+	unchanged := applied.GetResourceVersion() == existingObj.GetResourceVersion()
+	if dryRun {
+		unchanged = appliedYAML == liveYAML
+	}
+	switch {
+	case liveYAML == "":
+		result.Status = "created"
+	case unchanged:
+		result.Status = "unchanged"
+	default:
+		result.Status = "configured"
+	}
+	if dryRun {
+		result.Object = applied.Object
+		result.Diff = utils.GenerateHumanReadableDiff(liveYAML, appliedYAML, "live", "dry-run")
+	} else {
+		klog.Infof("Successfully applied resource: %s/%s", obj.GetKind(), obj.GetName())
+	}
+	return result
 }
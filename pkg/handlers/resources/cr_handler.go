@@ -2,13 +2,18 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xhilmi/kubedash/pkg/cluster"
 	"github.com/xhilmi/kubedash/pkg/common"
 	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -19,6 +24,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kubectl/pkg/describe"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 // CRHandler handles API operations for Custom Resources based on CRD name
@@ -81,6 +87,11 @@ func (h *CRHandler) List(c *gin.Context) {
 	// Create GVR from CRD
 	gvr := h.getGVRFromCRD(crd)
 
+	if wantsTable(c) {
+		h.listTable(c, crd, gvr)
+		return
+	}
+
 	// Create unstructured list object
 	crList := &unstructured.UnstructuredList{}
 	crList.SetGroupVersionKind(schema.GroupVersionKind{
@@ -107,6 +118,127 @@ func (h *CRHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, crList)
 }
 
+// listTable handles GET /:crd/:namespace?as=table. The API server prints the CRD's additionalPrinterColumns,
+// when it cannot, the columns are evaluated here from the cached list
+func (h *CRHandler) listTable(c *gin.Context, crd *apiextensionsv1.CustomResourceDefinition, gvr schema.GroupVersionResource) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
+
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "_all"
+	}
+	listNamespace := ""
+	if crd.Spec.Scope == apiextensionsv1.NamespaceScoped && namespace != "_all" {
+		listNamespace = namespace
+	}
+
+	table, err := fetchTable(c, gvr, listNamespace)
+	if err != nil {
+		klog.Warningf("Failed to get %s table from the API server, using printer columns: %v", crd.Name, err)
+		listOpts, err := parseSelectors(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if listNamespace != "" {
+			listOpts = append(listOpts, client.InNamespace(listNamespace))
+		}
+		crList := &unstructured.UnstructuredList{}
+		crList.SetGroupVersionKind(gvr.GroupVersion().WithKind(crd.Spec.Names.ListKind))
+		if err := cs.K8sClient.List(c.Request.Context(), crList, listOpts...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if table, err = printerColumnsTable(crd, gvr.Version, crList); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := finishTable(c, table, func(obj metav1.Object) bool {
+		return namespace != "_all" || obj.GetNamespace() == "" || rbac.CanAccessNamespace(user, cs.Name, obj.GetNamespace())
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, table)
+}
+
+// Watch streams an initial snapshot of the custom resources followed by watch events over SSE
+func (h *CRHandler) Watch(c *gin.Context) {
+	crdName := c.Param("crd")
+	if crdName == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CRD name is required"})
+		return
+	}
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
+	ctx := c.Request.Context()
+
+	crd, err := h.getCRDByName(ctx, cs.K8sClient, crdName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "CustomResourceDefinition not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	gvr := h.getGVRFromCRD(crd)
+	listGVK := schema.GroupVersionKind{
+		Group:   gvr.Group,
+		Version: gvr.Version,
+		Kind:    crd.Spec.Names.ListKind,
+	}
+
+	listOpts, err := parseSelectors(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "_all"
+	}
+	if crd.Spec.Scope == apiextensionsv1.NamespaceScoped && namespace != "_all" {
+		listOpts = append(listOpts, client.InNamespace(namespace))
+	}
+
+	crList := &unstructured.UnstructuredList{}
+	crList.SetGroupVersionKind(listGVK)
+	if err := cs.K8sClient.WatchClient.List(ctx, crList, listOpts...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := func(obj metav1.Object) bool {
+		return namespace != "_all" || obj.GetNamespace() == "" || rbac.CanAccessNamespace(user, cs.Name, obj.GetNamespace())
+	}
+	items := make([]unstructured.Unstructured, 0, len(crList.Items))
+	for i := range crList.Items {
+		trimObjectMeta(&crList.Items[i])
+		if filter(&crList.Items[i]) {
+			items = append(items, crList.Items[i])
+		}
+	}
+	crList.Items = items
+
+	opts := &client.ListOptions{}
+	opts.ApplyOptions(listOpts)
+	opts.Raw = &metav1.ListOptions{ResourceVersion: crList.GetResourceVersion()}
+	watchList := &unstructured.UnstructuredList{}
+	watchList.SetGroupVersionKind(listGVK)
+	watchInterface, err := cs.K8sClient.WatchClient.Watch(ctx, watchList, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start watch: " + err.Error()})
+		return
+	}
+	defer watchInterface.Stop()
+
+	streamWatch(c, crList, watchInterface, filter)
+}
+
 func (h *CRHandler) Get(c *gin.Context) {
 	crdName := c.Param("crd")
 	name := c.Param("name")
@@ -229,10 +361,12 @@ func (h *CRHandler) Create(c *gin.Context) {
 	}
 
 	if err := cs.K8sClient.Create(ctx, &cr); err != nil {
+		recordResourceHistory(c, crdName, cr.GetNamespace(), cr.GetName(), "create", "", crToYAML(&cr), false, err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	recordResourceHistory(c, crdName, cr.GetNamespace(), cr.GetName(), "create", "", crToYAML(&cr), true, "")
 	c.JSON(http.StatusCreated, cr)
 }
 
@@ -312,14 +446,214 @@ func (h *CRHandler) Update(c *gin.Context) {
 		updatedCR.SetNamespace(existingCR.GetNamespace())
 	}
 
+	prevYAML := crToYAML(existingCR)
 	if err := cs.K8sClient.Update(ctx, &updatedCR); err != nil {
+		recordResourceHistory(c, crdName, namespacedName.Namespace, name, "update", prevYAML, prevYAML, false, err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	recordResourceHistory(c, crdName, namespacedName.Namespace, name, "update", prevYAML, crToYAML(&updatedCR), true, "")
 	c.JSON(http.StatusOK, updatedCR)
 }
 
+// crPatchType maps the patchType query parameter to a patch type, custom resources default to a merge patch.
+// CRDs carry no patch strategy metadata so the API server rejects strategic merge patches for them;
+// without that metadata a strategic merge patch means the same as a JSON merge patch (maps are merged,
+// lists replaced), so it is sent as one as long as it uses no strategic merge directives.
+func crPatchType(patchType string, patch []byte) (types.PatchType, error) {
+	switch patchType {
+	case "", "merge":
+		return types.MergePatchType, nil
+	case "json":
+		return types.JSONPatchType, nil
+	case "strategic":
+		var body interface{}
+		if err := json.Unmarshal(patch, &body); err != nil {
+			return "", fmt.Errorf("invalid patch: %w", err)
+		}
+		if key := findPatchDirective(body); key != "" {
+			return "", fmt.Errorf("strategic merge directive %s is not supported for custom resources", key)
+		}
+		return types.MergePatchType, nil
+	default:
+		return "", fmt.Errorf("unsupported patchType %q, must be one of merge, json, strategic", patchType)
+	}
+}
+
+// findPatchDirective returns the first strategic merge patch directive key found in a patch
+func findPatchDirective(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if strings.HasPrefix(k, "$patch") || strings.HasPrefix(k, "$retainKeys") ||
+				strings.HasPrefix(k, "$setElementOrder/") || strings.HasPrefix(k, "$deleteFromPrimitiveList/") {
+				return k
+			}
+			if key := findPatchDirective(child); key != "" {
+				return key
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if key := findPatchDirective(child); key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// crToYAML renders a custom resource for history without server managed noise
+func crToYAML(cr *unstructured.Unstructured) string {
+	obj := cr.DeepCopy()
+	trimObjectMeta(obj)
+	b, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Patch handles PATCH /:crd/:namespace/:name with ?patchType=merge (default), json or strategic
+func (h *CRHandler) Patch(c *gin.Context) {
+	crdName := c.Param("crd")
+	name := c.Param("name")
+
+	if crdName == "" || name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CRD name and resource name are required"})
+		return
+	}
+
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	ctx := c.Request.Context()
+
+	patchBytes, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read patch data"})
+		return
+	}
+	patchType, err := crPatchType(c.Query("patchType"), patchBytes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	crd, err := h.getCRDByName(ctx, cs.K8sClient, crdName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "CustomResourceDefinition not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	gvr := h.getGVRFromCRD(crd)
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvr.GroupVersion().WithKind(crd.Spec.Names.Kind))
+
+	namespacedName := types.NamespacedName{Name: name}
+	if crd.Spec.Scope == apiextensionsv1.NamespaceScoped {
+		namespace := c.Param("namespace")
+		if namespace == "" || namespace == "_all" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "namespace is required for namespaced custom resources"})
+			return
+		}
+		namespacedName.Namespace = namespace
+	}
+
+	if err := cs.K8sClient.Get(ctx, namespacedName, cr); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Custom resource not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	prevYAML := crToYAML(cr)
+	if err := cs.K8sClient.Patch(ctx, cr, client.RawPatch(patchType, patchBytes)); err != nil {
+		recordResourceHistory(c, crdName, namespacedName.Namespace, name, "patch", prevYAML, prevYAML, false, err.Error())
+		status := http.StatusInternalServerError
+		if errors.IsInvalid(err) || errors.IsBadRequest(err) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordResourceHistory(c, crdName, namespacedName.Namespace, name, "patch", prevYAML, crToYAML(cr), true, "")
+	c.JSON(http.StatusOK, cr)
+}
+
+// ListHistory handles GET /:crd/:namespace/:name/history.
+// History routes bypass the RBAC middleware, so read access to the custom resource is checked here
+func (h *CRHandler) ListHistory(c *gin.Context) {
+	crdName := c.Param("crd")
+	namespace := c.Param("namespace")
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	if !rbac.CanAccess(user, crdName, string(common.VerbGet), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbGet), crdName, namespace, cs.Name),
+		})
+		return
+	}
+	listResourceHistory(c, crdName)
+}
+
+// GetHistoryDetail handles GET /:crd/:namespace/:name/history/:historyId
+func (h *CRHandler) GetHistoryDetail(c *gin.Context) {
+	getResourceHistoryDetail(c)
+}
+
+// searchFunc returns a global search function for the custom resources of a CRD
+func (h *CRHandler) searchFunc(crdName string) func(c *gin.Context, q string, limit int64) ([]common.SearchResult, error) {
+	return func(c *gin.Context, q string, limit int64) ([]common.SearchResult, error) {
+		if len(q) < 3 {
+			return nil, nil
+		}
+		cs := c.MustGet("cluster").(*cluster.ClientSet)
+		ctx := c.Request.Context()
+
+		crd, err := h.getCRDByName(ctx, cs.K8sClient, crdName)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				// The CRD is not installed in this cluster
+				return nil, nil
+			}
+			return nil, err
+		}
+		gvr := h.getGVRFromCRD(crd)
+		crList := &unstructured.UnstructuredList{}
+		crList.SetGroupVersionKind(gvr.GroupVersion().WithKind(crd.Spec.Names.ListKind))
+		if err := cs.K8sClient.List(ctx, crList); err != nil {
+			klog.Errorf("failed to list %s: %v", crdName, err)
+			return nil, err
+		}
+
+		results := make([]common.SearchResult, 0, limit)
+		for _, item := range crList.Items {
+			if !strings.Contains(strings.ToLower(item.GetName()), strings.ToLower(q)) {
+				continue
+			}
+			results = append(results, common.SearchResult{
+				ID:           string(item.GetUID()),
+				Name:         item.GetName(),
+				Namespace:    item.GetNamespace(),
+				ResourceType: crdName,
+				CreatedAt:    item.GetCreationTimestamp().String(),
+			})
+			if limit > 0 && int64(len(results)) >= limit {
+				break
+			}
+		}
+		return results, nil
+	}
+}
+
 func (h *CRHandler) Delete(c *gin.Context) {
 	crdName := c.Param("crd")
 	name := c.Param("name")
@@ -0,0 +1,310 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// maxSchedulePreview bounds the number of upcoming schedule times that can be requested
+	maxSchedulePreview = 50
+	// maxMissedSchedules mirrors the CronJob controller, which gives up counting after 100 missed start times
+	maxMissedSchedules = 100
+	// missedScheduleGrace leaves the controller some time to start a job before its schedule counts as missed
+	missedScheduleGrace = time.Minute
+)
+
+type CronJobHandler struct {
+	*GenericResourceHandler[*batchv1.CronJob, *batchv1.CronJobList]
+}
+
+func NewCronJobHandler() *CronJobHandler {
+	return &CronJobHandler{
+		GenericResourceHandler: NewGenericResourceHandler[*batchv1.CronJob, *batchv1.CronJobList](
+			"cronjobs",
+			false, // CronJobs are namespaced resources
+			false,
+		),
+	}
+}
+
+func (h *CronJobHandler) checkVerb(c *gin.Context, verb common.Verb) bool {
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	namespace := c.Param("namespace")
+	if !rbac.CanAccess(user, "cronjobs", string(verb), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(verb), "cronjobs", namespace, cs.Name),
+		})
+		return false
+	}
+	return true
+}
+
+func (h *CronJobHandler) getCronJob(c *gin.Context) (*batchv1.CronJob, bool) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	var cronJob batchv1.CronJob
+	key := types.NamespacedName{Namespace: c.Param("namespace"), Name: c.Param("name")}
+	if err := cs.K8sClient.Get(c.Request.Context(), key, &cronJob); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "CronJob not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return &cronJob, true
+}
+
+// TriggerCronJobHandler handles POST /cronjobs/:namespace/:name/trigger
+// It creates a Job from spec.jobTemplate like `kubectl create job --from=cronjob/<name>`.
+// Requires 'trigger' verb permission
+func (h *CronJobHandler) TriggerCronJobHandler(c *gin.Context) {
+	if !h.checkVerb(c, common.VerbTrigger) {
+		return
+	}
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	var req struct {
+		JobName string `json:"jobName"` // Optional, generated from the CronJob name if empty
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	cronJob, ok := h.getCronJob(c)
+	if !ok {
+		return
+	}
+
+	jobName := req.JobName
+	if jobName == "" {
+		suffix := "-manual-" + strconv.FormatInt(time.Now().Unix(), 10)
+		base := cronJob.Name
+		// Job names end up in the job-name label, which is limited to 63 characters
+		if len(base)+len(suffix) > 63 {
+			base = base[:63-len(suffix)]
+		}
+		jobName = base + suffix
+	}
+
+	annotations := map[string]string{"cronjob.kubernetes.io/instantiate": "manual"}
+	for k, v := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   cronJob.Namespace,
+			Labels:      cronJob.Spec.JobTemplate.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob")),
+			},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+
+	details := map[string]interface{}{
+		"action": "trigger",
+		"job":    jobName,
+	}
+	if err := cs.K8sClient.Create(c.Request.Context(), job); err != nil {
+		klog.Errorf("Failed to trigger cronjob %s/%s: %v", cronJob.Namespace, cronJob.Name, err)
+		h.recordActionResult(c, cronJob.Namespace, cronJob.Name, "trigger", details, err)
+		status := http.StatusInternalServerError
+		if errors.IsAlreadyExists(err) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": "Failed to create job: " + err.Error()})
+		return
+	}
+
+	h.recordActionHistory(c, cronJob.Namespace, cronJob.Name, "trigger", details)
+
+	klog.Infof("User %s triggered cronjob %s/%s as job %s in cluster %s", user.Key(), cronJob.Namespace, cronJob.Name, jobName, cs.Name)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("Job %s created from cronjob %s", jobName, cronJob.Name),
+		"job":     job,
+	})
+}
+
+// setSuspend toggles spec.suspend, handles POST /cronjobs/:namespace/:name/suspend and /resume.
+// Requires 'suspend' verb permission
+func (h *CronJobHandler) setSuspend(c *gin.Context, suspend bool) {
+	if !h.checkVerb(c, common.VerbSuspend) {
+		return
+	}
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	action := "resume"
+	if suspend {
+		action = "suspend"
+	}
+
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"suspend": suspend,
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create patch: " + err.Error()})
+		return
+	}
+
+	cronJob := &batchv1.CronJob{}
+	cronJob.Name = name
+	cronJob.Namespace = namespace
+	if err := cs.K8sClient.Patch(c.Request.Context(), cronJob, client.RawPatch(types.MergePatchType, patchBytes)); err != nil {
+		klog.Errorf("Failed to %s cronjob %s/%s: %v", action, namespace, name, err)
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "CronJob not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s cronjob: %v", action, err)})
+		return
+	}
+
+	h.recordActionHistory(c, namespace, name, action, map[string]interface{}{
+		"action":  action,
+		"suspend": suspend,
+	})
+
+	klog.Infof("User %s set suspend=%v on cronjob %s/%s in cluster %s", user.Key(), suspend, namespace, name, cs.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("CronJob %sd successfully", action),
+		"suspend": suspend,
+	})
+}
+
+func (h *CronJobHandler) SuspendCronJobHandler(c *gin.Context) {
+	h.setSuspend(c, true)
+}
+
+func (h *CronJobHandler) ResumeCronJobHandler(c *gin.Context) {
+	h.setSuspend(c, false)
+}
+
+// parseCronSchedule parses the schedule of a CronJob together with its time zone
+func parseCronSchedule(cronJob *batchv1.CronJob) (cron.Schedule, *time.Location, error) {
+	schedule, err := cron.ParseStandard(cronJob.Spec.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule %q: %w", cronJob.Spec.Schedule, err)
+	}
+	loc := time.Local
+	if cronJob.Spec.TimeZone != nil && *cronJob.Spec.TimeZone != "" {
+		loc, err = time.LoadLocation(*cronJob.Spec.TimeZone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid time zone %q: %w", *cronJob.Spec.TimeZone, err)
+		}
+	}
+	return schedule, loc, nil
+}
+
+// missedSchedules returns the schedule times between the last run and now that did not start a job,
+// counted from the same starting point the CronJob controller uses
+func missedSchedules(cronJob *batchv1.CronJob, schedule cron.Schedule, loc *time.Location, now time.Time) []time.Time {
+	earliest := cronJob.CreationTimestamp.Time
+	if cronJob.Status.LastScheduleTime != nil {
+		earliest = cronJob.Status.LastScheduleTime.Time
+	}
+	if cronJob.Spec.StartingDeadlineSeconds != nil {
+		deadline := now.Add(-time.Duration(*cronJob.Spec.StartingDeadlineSeconds) * time.Second)
+		if deadline.After(earliest) {
+			earliest = deadline
+		}
+	}
+
+	missed := []time.Time{}
+	cutoff := now.Add(-missedScheduleGrace)
+	for t := schedule.Next(earliest.In(loc)); !t.After(cutoff); t = schedule.Next(t) {
+		// A schedule that never matches, like February 30th, has no next time
+		if t.IsZero() {
+			break
+		}
+		missed = append(missed, t)
+		if len(missed) >= maxMissedSchedules {
+			break
+		}
+	}
+	return missed
+}
+
+// ScheduleCronJobHandler handles GET /cronjobs/:namespace/:name/schedule?count=N
+// It previews the next schedule times in the CronJob's time zone and reports missed schedules
+func (h *CronJobHandler) ScheduleCronJobHandler(c *gin.Context) {
+	count := 5
+	if v := c.Query("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid count parameter"})
+			return
+		}
+		count = min(n, maxSchedulePreview)
+	}
+
+	cronJob, ok := h.getCronJob(c)
+	if !ok {
+		return
+	}
+	schedule, loc, err := parseCronSchedule(cronJob)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	next := make([]time.Time, 0, count)
+	for t := schedule.Next(now.In(loc)); len(next) < count; t = schedule.Next(t) {
+		if t.IsZero() {
+			break
+		}
+		next = append(next, t)
+	}
+
+	suspended := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+	missed := []time.Time{}
+	if !suspended {
+		missed = missedSchedules(cronJob, schedule, loc, now)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schedule":         cronJob.Spec.Schedule,
+		"timeZone":         loc.String(),
+		"suspended":        suspended,
+		"next":             next,
+		"lastScheduleTime": cronJob.Status.LastScheduleTime,
+		"missedSchedules":  missed,
+		"missedCount":      len(missed),
+	})
+}
+
+func (h *CronJobHandler) registerCustomRoutes(group *gin.RouterGroup) {
+	group.POST("/:namespace/:name/trigger", h.TriggerCronJobHandler)
+	group.POST("/:namespace/:name/suspend", h.SuspendCronJobHandler)
+	group.POST("/:namespace/:name/resume", h.ResumeCronJobHandler)
+	group.GET("/:namespace/:name/schedule", h.ScheduleCronJobHandler)
+}
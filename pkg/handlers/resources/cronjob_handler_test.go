@@ -0,0 +1,42 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMissedSchedules(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lastSchedule := metav1.NewTime(now.Add(-3 * time.Hour))
+
+	tests := []struct {
+		name     string
+		schedule string
+		want     int
+	}{
+		// The run at 12:00 is still within the grace period
+		{"hourly", "0 * * * *", 2},
+		{"not due yet", "0 0 1 1 *", 0},
+		{"never matches", "0 0 30 2 *", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := cron.ParseStandard(tt.schedule)
+			require.NoError(t, err)
+			cronJob := &batchv1.CronJob{Status: batchv1.CronJobStatus{LastScheduleTime: &lastSchedule}}
+
+			missed := missedSchedules(cronJob, schedule, time.UTC, now)
+			require.NotNil(t, missed)
+			assert.Len(t, missed, tt.want)
+			for _, m := range missed {
+				assert.False(t, m.IsZero())
+			}
+		})
+	}
+}
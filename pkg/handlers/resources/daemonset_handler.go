@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+type DaemonSetHandler struct {
+	*GenericResourceHandler[*appsv1.DaemonSet, *appsv1.DaemonSetList]
+}
+
+func NewDaemonSetHandler() *DaemonSetHandler {
+	return &DaemonSetHandler{
+		GenericResourceHandler: NewGenericResourceHandler[*appsv1.DaemonSet, *appsv1.DaemonSetList](
+			"daemonsets",
+			false, // DaemonSets are namespaced resources
+			true,
+		),
+	}
+}
+
+func (h *DaemonSetHandler) Restart(c *gin.Context, namespace, name string) error {
+	daemonset := &appsv1.DaemonSet{}
+	daemonset.Name = name
+	daemonset.Namespace = namespace
+
+	return restartPodTemplate(c, daemonset)
+}
+
+// RestartDaemonSetHandler handles POST /daemonsets/:namespace/:name/restart
+// Requires 'restart' verb permission (fine-grained control)
+func (h *DaemonSetHandler) RestartDaemonSetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	if !rbac.CanAccess(user, "daemonsets", string(common.VerbRestart), cs.Name, namespace) {
+		klog.Warningf("User %s denied restart permission for daemonset %s/%s", user.Key(), namespace, name)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbRestart), "daemonsets", namespace, cs.Name),
+		})
+		return
+	}
+
+	if err := h.Restart(c, namespace, name); err != nil {
+		klog.Errorf("Failed to restart daemonset %s/%s: %v", namespace, name, err)
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "DaemonSet not found: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restart daemonset: " + err.Error()})
+		return
+	}
+
+	h.recordActionHistory(c, namespace, name, "restart", map[string]interface{}{
+		"action": "restart",
+		"time":   time.Now().Format(time.RFC3339),
+	})
+
+	klog.Infof("User %s restarted daemonset %s/%s in cluster %s", user.Key(), namespace, name, cs.Name)
+	c.JSON(http.StatusOK, gin.H{"message": "DaemonSet restarted successfully"})
+}
+
+func (h *DaemonSetHandler) registerCustomRoutes(group *gin.RouterGroup) {
+	group.POST("/:namespace/:name/restart", h.RestartDaemonSetHandler)
+}
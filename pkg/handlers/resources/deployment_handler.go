@@ -39,64 +39,11 @@ func NewDeploymentHandler() *DeploymentHandler {
 }
 
 func (h *DeploymentHandler) Restart(c *gin.Context, namespace, name string) error {
-	cs := c.MustGet("cluster").(*cluster.ClientSet)
-	
-	// Use strategic merge patch to only update the restart annotation
-	// This avoids triggering full "update" permission
-	patch := map[string]interface{}{
-		"spec": map[string]interface{}{
-			"template": map[string]interface{}{
-				"metadata": map[string]interface{}{
-					"annotations": map[string]interface{}{
-						"kite.kubernetes.io/restartedAt": time.Now().Format(time.RFC3339),
-					},
-				},
-			},
-		},
-	}
-	
-	patchBytes, err := json.Marshal(patch)
-	if err != nil {
-		return fmt.Errorf("failed to marshal patch: %w", err)
-	}
-	
 	deployment := &appsv1.Deployment{}
 	deployment.Name = name
 	deployment.Namespace = namespace
-	
-	return cs.K8sClient.Patch(c.Request.Context(), deployment, client.RawPatch(types.StrategicMergePatchType, patchBytes))
-}
-
-// Helper function to record action history
-func (h *DeploymentHandler) recordActionHistory(c *gin.Context, namespace, name, actionType string, details map[string]interface{}) {
-	cs := c.MustGet("cluster").(*cluster.ClientSet)
-	user := c.MustGet("user").(model.User)
 
-	// Get current deployment state for YAML
-	var deployment appsv1.Deployment
-	deploymentYAML := ""
-	if err := cs.K8sClient.Get(c.Request.Context(), types.NamespacedName{Namespace: namespace, Name: name}, &deployment); err == nil {
-		deploymentYAML = h.ToYAML(&deployment)
-	}
-
-	// Build details string
-	detailsJSON, _ := json.Marshal(details)
-
-	history := model.ResourceHistory{
-		ClusterName:   cs.Name,
-		ResourceType:  "deployments",
-		ResourceName:  name,
-		Namespace:     namespace,
-		OperationType: actionType,
-		ResourceYAML:  deploymentYAML,
-		PreviousYAML:  string(detailsJSON), // Store action details in PreviousYAML field
-		Success:       true,
-		ErrorMessage:  "",
-		OperatorID:    user.ID,
-	}
-	if err := model.DB.Create(&history).Error; err != nil {
-		klog.Errorf("Failed to create resource history: %v", err)
-	}
+	return restartPodTemplate(c, deployment)
 }
 
 // RestartDeploymentHandler handles POST /deployments/:namespace/:name/restart
@@ -935,6 +882,12 @@ func (h *DeploymentHandler) registerCustomRoutes(group *gin.RouterGroup) {
 	group.POST("/:namespace/:name/scale", h.ScaleDeploymentHandler)
 	group.PUT("/:namespace/:name/edit", h.EditDeploymentHandler)
 	group.POST("/:namespace/:name/rollback", h.RollbackDeploymentHandler)
+	group.GET("/:namespace/:name/revisions", h.ListRevisionsHandler)
+	group.POST("/:namespace/:name/revisions/rollback", h.RollbackRevisionHandler)
+	group.GET("/:namespace/:name/revisions/:revision", h.GetRevisionHandler)
+	group.POST("/:namespace/:name/rollout/pause", h.PauseRolloutHandler)
+	group.POST("/:namespace/:name/rollout/resume", h.ResumeRolloutHandler)
+	group.GET("/:namespace/:name/rollout/status", h.RolloutStatusHandler)
 	group.POST("/:namespace/:name/suspend", h.SuspendHelmReleaseHandler)
 	group.POST("/:namespace/:name/resume", h.ResumeHelmReleaseHandler)
 	group.GET("/:namespace/:name/helm/detect", h.DetectHelmReleaseHandler)
@@ -0,0 +1,563 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	"github.com/xhilmi/kubedash/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// deploymentRevision describes one revision of a Deployment, backed by the ReplicaSet that holds its pod template
+type deploymentRevision struct {
+	Revision    int64       `json:"revision"`
+	ReplicaSet  string      `json:"replicaSet"`
+	CreatedAt   metav1.Time `json:"createdAt"`
+	Images      []string    `json:"images"`
+	ChangeCause string      `json:"changeCause,omitempty"`
+	Replicas    int32       `json:"replicas"`
+	Current     bool        `json:"current"`
+}
+
+// rollbackAnnotationsToSkip lists the deployment annotations that are kept as they are
+// and not copied from the ReplicaSet on rollback, same as `kubectl rollout undo`
+var rollbackAnnotationsToSkip = map[string]bool{
+	corev1.LastAppliedConfigAnnotation:       true,
+	deploymentutil.RevisionAnnotation:        true,
+	deploymentutil.RevisionHistoryAnnotation: true,
+	deploymentutil.DesiredReplicasAnnotation: true,
+	deploymentutil.MaxReplicasAnnotation:     true,
+	appsv1.DeprecatedRollbackTo:              true,
+}
+
+// getDeploymentReplicaSets returns the ReplicaSets controlled by the deployment, newest revision first
+func (h *DeploymentHandler) getDeploymentReplicaSets(ctx context.Context, cs *cluster.ClientSet, deployment *appsv1.Deployment) ([]appsv1.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment selector: %w", err)
+	}
+
+	var rsList appsv1.ReplicaSetList
+	if err := cs.K8sClient.List(ctx, &rsList, client.InNamespace(deployment.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	replicaSets := make([]appsv1.ReplicaSet, 0, len(rsList.Items))
+	for _, rs := range rsList.Items {
+		if metav1.IsControlledBy(&rs, deployment) {
+			replicaSets = append(replicaSets, rs)
+		}
+	}
+	sort.Slice(replicaSets, func(i, j int) bool {
+		ri, _ := deploymentutil.Revision(&replicaSets[i])
+		rj, _ := deploymentutil.Revision(&replicaSets[j])
+		return ri > rj
+	})
+	return replicaSets, nil
+}
+
+// findRevision returns the ReplicaSet for the given revision.
+// Revision 0 means the revision before the current one.
+func findRevision(replicaSets []appsv1.ReplicaSet, revision int64) (*appsv1.ReplicaSet, error) {
+	if revision == 0 {
+		// replicaSets are sorted newest first
+		if len(replicaSets) < 2 {
+			return nil, fmt.Errorf("no rollout history found")
+		}
+		return &replicaSets[1], nil
+	}
+	for i := range replicaSets {
+		if v, err := deploymentutil.Revision(&replicaSets[i]); err == nil && v == revision {
+			return &replicaSets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unable to find specified revision %d in history", revision)
+}
+
+// templateYAML renders a pod template without the pod-template-hash label so revisions compare cleanly
+func templateYAML(template *corev1.PodTemplateSpec) string {
+	t := template.DeepCopy()
+	delete(t.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	b, err := yaml.Marshal(t)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// equalIgnoreHash reports whether two pod templates are equal, ignoring the pod-template-hash label
+func equalIgnoreHash(t1, t2 *corev1.PodTemplateSpec) bool {
+	t1Copy := t1.DeepCopy()
+	t2Copy := t2.DeepCopy()
+	delete(t1Copy.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	delete(t2Copy.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return equality.Semantic.DeepEqual(t1Copy, t2Copy)
+}
+
+func (h *DeploymentHandler) getDeployment(c *gin.Context) (*appsv1.Deployment, bool) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	var deployment appsv1.Deployment
+	key := types.NamespacedName{Namespace: c.Param("namespace"), Name: c.Param("name")}
+	if err := cs.K8sClient.Get(c.Request.Context(), key, &deployment); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return &deployment, true
+}
+
+// ListRevisionsHandler handles GET /deployments/:namespace/:name/revisions
+// It lists the rollout history of a deployment, like `kubectl rollout history`
+func (h *DeploymentHandler) ListRevisionsHandler(c *gin.Context) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	deployment, ok := h.getDeployment(c)
+	if !ok {
+		return
+	}
+
+	replicaSets, err := h.getDeploymentReplicaSets(c.Request.Context(), cs, deployment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list replicasets: " + err.Error()})
+		return
+	}
+
+	currentRevision, _ := deploymentutil.Revision(deployment)
+	revisions := make([]deploymentRevision, 0, len(replicaSets))
+	for _, rs := range replicaSets {
+		revision, err := deploymentutil.Revision(&rs)
+		if err != nil {
+			continue
+		}
+		images := make([]string, 0, len(rs.Spec.Template.Spec.Containers))
+		for _, container := range rs.Spec.Template.Spec.Containers {
+			images = append(images, container.Image)
+		}
+		revisions = append(revisions, deploymentRevision{
+			Revision:    revision,
+			ReplicaSet:  rs.Name,
+			CreatedAt:   rs.CreationTimestamp,
+			Images:      images,
+			ChangeCause: rs.Annotations["kubernetes.io/change-cause"],
+			Replicas:    rs.Status.Replicas,
+			Current:     revision == currentRevision,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"currentRevision": currentRevision,
+		"revisions":       revisions,
+	})
+}
+
+// GetRevisionHandler handles GET /deployments/:namespace/:name/revisions/:revision
+// It returns the pod template of a revision and its diff against the current template,
+// or against another revision when ?compare=<revision> is set
+func (h *DeploymentHandler) GetRevisionHandler(c *gin.Context) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	revision, err := strconv.ParseInt(c.Param("revision"), 10, 64)
+	if err != nil || revision <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+
+	deployment, ok := h.getDeployment(c)
+	if !ok {
+		return
+	}
+	replicaSets, err := h.getDeploymentReplicaSets(c.Request.Context(), cs, deployment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list replicasets: " + err.Error()})
+		return
+	}
+	rs, err := findRevision(replicaSets, revision)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	baseYAML := templateYAML(&deployment.Spec.Template)
+	baseLabel := "current"
+	if compare := c.Query("compare"); compare != "" {
+		compareRevision, err := strconv.ParseInt(compare, 10, 64)
+		if err != nil || compareRevision <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid compare revision"})
+			return
+		}
+		compareRS, err := findRevision(replicaSets, compareRevision)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		baseYAML = templateYAML(&compareRS.Spec.Template)
+		baseLabel = fmt.Sprintf("revision %d", compareRevision)
+	}
+
+	revisionYAML := templateYAML(&rs.Spec.Template)
+	c.JSON(http.StatusOK, gin.H{
+		"revision":   revision,
+		"replicaSet": rs.Name,
+		"template":   revisionYAML,
+		"diff":       utils.GenerateHumanReadableDiff(baseYAML, revisionYAML, baseLabel, fmt.Sprintf("revision %d", revision)),
+	})
+}
+
+// RollbackRevisionHandler handles POST /deployments/:namespace/:name/revisions/rollback
+// It restores the pod template of a previous revision the same way `kubectl rollout undo` does.
+// Requires 'rollback' verb permission, shared with the Helm rollback
+func (h *DeploymentHandler) RollbackRevisionHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	if !rbac.CanAccess(user, "deployments", string(common.VerbRollback), cs.Name, namespace) {
+		klog.Warningf("User %s denied rollback permission for deployment %s/%s", user.Key(), namespace, name)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbRollback), "deployments", namespace, cs.Name),
+		})
+		return
+	}
+
+	var req struct {
+		Revision int64 `json:"revision" binding:"gte=0"` // Optional, if not provided rollback to previous
+	}
+	// An empty body rolls back to the previous revision
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	deployment, ok := h.getDeployment(c)
+	if !ok {
+		return
+	}
+	if deployment.Spec.Paused {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot rollback a paused deployment, resume it first"})
+		return
+	}
+
+	replicaSets, err := h.getDeploymentReplicaSets(c.Request.Context(), cs, deployment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list replicasets: " + err.Error()})
+		return
+	}
+	rs, err := findRevision(replicaSets, req.Revision)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	toRevision, _ := deploymentutil.Revision(rs)
+	fromRevision, _ := deploymentutil.Revision(deployment)
+
+	if equalIgnoreHash(&rs.Spec.Template, &deployment.Spec.Template) {
+		c.JSON(http.StatusOK, gin.H{
+			"message":  fmt.Sprintf("Rollback skipped, current template already matches revision %d", toRevision),
+			"revision": toRevision,
+		})
+		return
+	}
+
+	// Restore the pod template without the hash label and carry over the ReplicaSet annotations
+	template := rs.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	annotations := map[string]string{}
+	for k := range rollbackAnnotationsToSkip {
+		if v, ok := deployment.Annotations[k]; ok {
+			annotations[k] = v
+		}
+	}
+	for k, v := range rs.Annotations {
+		if !rollbackAnnotationsToSkip[k] {
+			annotations[k] = v
+		}
+	}
+
+	patch, err := json.Marshal([]interface{}{
+		map[string]interface{}{"op": "replace", "path": "/spec/template", "value": template},
+		map[string]interface{}{"op": "replace", "path": "/metadata/annotations", "value": annotations},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create patch: " + err.Error()})
+		return
+	}
+
+	details := map[string]interface{}{
+		"action":       "rollback",
+		"source":       "native",
+		"fromRevision": fromRevision,
+		"toRevision":   toRevision,
+		"replicaSet":   rs.Name,
+	}
+	if err := cs.K8sClient.Patch(c.Request.Context(), deployment, client.RawPatch(types.JSONPatchType, patch)); err != nil {
+		klog.Errorf("Failed to rollback deployment %s/%s to revision %d: %v", namespace, name, toRevision, err)
+		h.recordActionResult(c, namespace, name, "rollback", details, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to restore revision %d: %v", toRevision, err)})
+		return
+	}
+
+	h.recordActionHistory(c, namespace, name, "rollback", details)
+
+	klog.Infof("User %s rolled back deployment %s/%s to revision %d in cluster %s",
+		user.Key(), namespace, name, toRevision, cs.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  fmt.Sprintf("Deployment rolled back to revision %d", toRevision),
+		"revision": toRevision,
+	})
+}
+
+// setPaused toggles spec.paused, handles POST /deployments/:namespace/:name/rollout/pause and /rollout/resume.
+// It requires the 'pause' verb, granted by 'patch'
+func (h *DeploymentHandler) setPaused(c *gin.Context, paused bool) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	if !rbac.CanAccess(user, "deployments", string(common.VerbPause), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbPause), "deployments", namespace, cs.Name),
+		})
+		return
+	}
+
+	action := "resume"
+	if paused {
+		action = "pause"
+	}
+
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"paused": paused,
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create patch: " + err.Error()})
+		return
+	}
+
+	deployment := &appsv1.Deployment{}
+	deployment.Name = name
+	deployment.Namespace = namespace
+	if err := cs.K8sClient.Patch(c.Request.Context(), deployment, client.RawPatch(types.StrategicMergePatchType, patchBytes)); err != nil {
+		klog.Errorf("Failed to %s rollout of deployment %s/%s: %v", action, namespace, name, err)
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s rollout: %v", action, err)})
+		return
+	}
+
+	h.recordActionHistory(c, namespace, name, action, map[string]interface{}{
+		"action": action,
+		"paused": paused,
+	})
+
+	klog.Infof("User %s set paused=%v on deployment %s/%s in cluster %s", user.Key(), paused, namespace, name, cs.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Deployment rollout %sd successfully", action),
+		"paused":  paused,
+	})
+}
+
+func (h *DeploymentHandler) PauseRolloutHandler(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+func (h *DeploymentHandler) ResumeRolloutHandler(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+// rolloutStatus is a snapshot of a deployment rollout as reported by the status stream
+type rolloutStatus struct {
+	Generation          int64                        `json:"generation"`
+	ObservedGeneration  int64                        `json:"observedGeneration"`
+	Paused              bool                         `json:"paused"`
+	Replicas            int32                        `json:"replicas"`
+	CurrentReplicas     int32                        `json:"currentReplicas"`
+	UpdatedReplicas     int32                        `json:"updatedReplicas"`
+	ReadyReplicas       int32                        `json:"readyReplicas"`
+	AvailableReplicas   int32                        `json:"availableReplicas"`
+	UnavailableReplicas int32                        `json:"unavailableReplicas"`
+	Conditions          []appsv1.DeploymentCondition `json:"conditions,omitempty"`
+	Message             string                       `json:"message"`
+	Done                bool                         `json:"done"`
+	Failed              bool                         `json:"failed"`
+}
+
+// getRolloutStatus mirrors `kubectl rollout status` for deployments
+func getRolloutStatus(deployment *appsv1.Deployment) rolloutStatus {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := rolloutStatus{
+		Generation:          deployment.Generation,
+		ObservedGeneration:  deployment.Status.ObservedGeneration,
+		Paused:              deployment.Spec.Paused,
+		Replicas:            desired,
+		CurrentReplicas:     deployment.Status.Replicas,
+		UpdatedReplicas:     deployment.Status.UpdatedReplicas,
+		ReadyReplicas:       deployment.Status.ReadyReplicas,
+		AvailableReplicas:   deployment.Status.AvailableReplicas,
+		UnavailableReplicas: deployment.Status.UnavailableReplicas,
+		Conditions:          deployment.Status.Conditions,
+	}
+
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		status.Message = "Waiting for deployment spec update to be observed..."
+		return status
+	}
+	cond := deploymentutil.GetDeploymentCondition(deployment.Status, appsv1.DeploymentProgressing)
+	switch {
+	case cond != nil && cond.Reason == deploymentutil.TimedOutReason:
+		status.Message = fmt.Sprintf("deployment %q exceeded its progress deadline", deployment.Name)
+		status.Failed = true
+	case deployment.Status.UpdatedReplicas < desired:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...", deployment.Name, deployment.Status.UpdatedReplicas, desired)
+	case deployment.Status.Replicas > deployment.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...", deployment.Name, deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
+	case deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas:
+		status.Message = fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...", deployment.Name, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+	default:
+		status.Message = fmt.Sprintf("deployment %q successfully rolled out", deployment.Name)
+		status.Done = true
+	}
+	if deployment.Spec.Paused && !status.Done && !status.Failed {
+		status.Message = fmt.Sprintf("deployment %q rollout is paused", deployment.Name)
+	}
+	return status
+}
+
+// rolloutConditionKey identifies the state of a deployment condition so only real changes are reported
+func rolloutConditionKey(cond *appsv1.DeploymentCondition) string {
+	if cond == nil {
+		return ""
+	}
+	return string(cond.Status) + "/" + cond.Reason
+}
+
+// RolloutStatusHandler handles GET /deployments/:namespace/:name/rollout/status
+// It streams the rollout progress over SSE: "status" events carry the replica counts,
+// "condition" events report Progressing/Available changes, and the stream ends with
+// "completed" on success or "failed" once the progress deadline is exceeded.
+func (h *DeploymentHandler) RolloutStatusHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	ctx := c.Request.Context()
+
+	var deployment appsv1.Deployment
+	if err := cs.K8sClient.WatchClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &deployment); err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Deployment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	w, err := cs.K8sClient.WatchClient.Watch(ctx, &appsv1.DeploymentList{},
+		client.InNamespace(namespace),
+		client.MatchingFields{"metadata.name": name},
+		&client.ListOptions{Raw: &metav1.ListOptions{ResourceVersion: deployment.ResourceVersion}},
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to start watch: %v", err)})
+		return
+	}
+	defer w.Stop()
+
+	conditions := map[appsv1.DeploymentConditionType]string{}
+	// report returns true once the rollout reached a final state
+	report := func(d *appsv1.Deployment) bool {
+		for _, condType := range []appsv1.DeploymentConditionType{appsv1.DeploymentProgressing, appsv1.DeploymentAvailable} {
+			cond := deploymentutil.GetDeploymentCondition(d.Status, condType)
+			key := rolloutConditionKey(cond)
+			if previous, ok := conditions[condType]; ok && previous == key {
+				continue
+			}
+			conditions[condType] = key
+			if cond != nil {
+				_ = writeSSE(c, "condition", cond)
+			}
+		}
+
+		status := getRolloutStatus(d)
+		_ = writeSSE(c, "status", status)
+		switch {
+		case status.Failed:
+			_ = writeSSE(c, "failed", gin.H{"message": status.Message})
+			return true
+		case status.Done:
+			_ = writeSSE(c, "completed", gin.H{"message": status.Message})
+			return true
+		}
+		return false
+	}
+
+	if report(&deployment) {
+		return
+	}
+
+	// Keep-alive pings
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	flusher, _ := c.Writer.(http.Flusher)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = fmt.Fprintf(c.Writer, ": ping\n\n")
+			flusher.Flush()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				_ = writeSSE(c, "close", gin.H{"message": "watch channel closed"})
+				return
+			}
+			switch event.Type {
+			case watch.Modified, watch.Added:
+				d, ok := event.Object.(*appsv1.Deployment)
+				if !ok {
+					continue
+				}
+				if report(d) {
+					return
+				}
+			case watch.Deleted:
+				_ = writeSSE(c, "failed", gin.H{"message": fmt.Sprintf("deployment %q was deleted", name)})
+				return
+			case watch.Error:
+				_ = writeSSE(c, "error", gin.H{"error": "watch error"})
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package resources
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRollbackRevisionOptionalBody(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+	cs := newTestClientSet(t, newTestAPIServer(t, nil), deployment)
+	r := newTestRouter(cs)
+	r.POST("/deployments/:namespace/:name/revisions/rollback", NewDeploymentHandler().RollbackRevisionHandler)
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		// Without a revision the previous one is used, the deployment has no history
+		{"empty body", "", http.StatusNotFound},
+		{"empty object", "{}", http.StatusNotFound},
+		{"negative revision", `{"revision":-1}`, http.StatusBadRequest},
+		{"malformed body", `{"revision":`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/deployments/default/web/revisions/rollback", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+		})
+	}
+}
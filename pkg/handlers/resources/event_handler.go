@@ -2,12 +2,22 @@ package resources
 
 import (
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	"github.com/xhilmi/kubedash/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
 )
 
 type EventHandler struct {
@@ -24,27 +34,52 @@ func NewEventHandler() *EventHandler {
 	}
 }
 
+// ListResourceEvents handles GET /events/resources?resource=&namespace=&name=
+// resource is a plural name such as pods, or <plural>.<group> for custom resources
 func (h *EventHandler) ListResourceEvents(c *gin.Context) {
 	name := c.Query("name")
 	namespace := c.Query("namespace")
 	resource := c.Query("resource")
 	cs := c.MustGet("cluster").(*cluster.ClientSet)
-	target, err := GetResource(c, resource, namespace, name)
+	user := c.MustGet("user").(model.User)
+	ctx := c.Request.Context()
 
+	gvk, err := cs.K8sClient.Mapper.KindForResource(kube.ParseResourceName(resource).WithVersion(""))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get resource: " + err.Error()})
 		return
 	}
-
-	objType, err := meta.TypeAccessor(target)
+	mapping, err := cs.K8sClient.Mapper.MappingFor(gvk)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to access object type info: " + err.Error()})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get resource: " + err.Error()})
+		return
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		namespace = ""
+	}
+
+	resourceName := kube.ResourceName(mapping.Resource)
+	if !rbac.CanAccess(user, resourceName, string(common.VerbGet), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbGet), resourceName, namespace, cs.Name),
+		})
 		return
 	}
-	obj := target.(metav1.Object)
-	events, err := cs.K8sClient.ClientSet.CoreV1().Events(obj.GetNamespace()).List(c.Request.Context(), metav1.ListOptions{
-		FieldSelector: "involvedObject.kind=" + objType.GetKind() +
-			",involvedObject.apiVersion=" + objType.GetAPIVersion() +
+
+	target := &metav1.PartialObjectMetadata{}
+	target.SetGroupVersionKind(gvk)
+	if err := cs.K8sClient.WatchClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, target); err != nil {
+		status := http.StatusInternalServerError
+		if errors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": "Failed to get resource: " + err.Error()})
+		return
+	}
+
+	events, err := cs.K8sClient.ClientSet.CoreV1().Events(target.GetNamespace()).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=" + gvk.Kind +
+			",involvedObject.apiVersion=" + gvk.GroupVersion().String() +
 			",involvedObject.name=" + name,
 	})
 
@@ -53,9 +88,69 @@ func (h *EventHandler) ListResourceEvents(c *gin.Context) {
 		return
 	}
 
+	archived, err := model.ListArchivedEvents(cs.Name, target.GetNamespace(), gvk.Kind, gvk.GroupVersion().String(), name)
+	if err != nil {
+		klog.Warningf("Failed to list archived events of %s %s/%s: %v", resourceName, target.GetNamespace(), name, err)
+	}
+	mergeArchivedEvents(events, archived)
+
 	c.JSON(http.StatusOK, events)
 }
 
+// mergeArchivedEvents adds the archived events the API server already dropped to the
+// live ones, the most recent first. Archived events are marked with an annotation.
+func mergeArchivedEvents(events *corev1.EventList, archived []model.ArchivedEvent) {
+	live := make(map[types.UID]bool, len(events.Items))
+	for _, e := range events.Items {
+		live[e.UID] = true
+	}
+	merged := false
+	for _, a := range archived {
+		if live[types.UID(a.UID)] {
+			continue
+		}
+		events.Items = append(events.Items, archivedToEvent(a))
+		merged = true
+	}
+	if !merged {
+		return
+	}
+	lastSeen := func(e *corev1.Event) time.Time {
+		_, last := utils.EventTimes(e)
+		return last
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return lastSeen(&events.Items[j]).Before(lastSeen(&events.Items[i]))
+	})
+}
+
+func archivedToEvent(a model.ArchivedEvent) corev1.Event {
+	return corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        a.Name,
+			Namespace:   a.Namespace,
+			UID:         types.UID(a.UID),
+			Annotations: map[string]string{common.ArchivedEventAnnotation: "true"},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       a.InvolvedKind,
+			APIVersion: a.InvolvedAPIVersion,
+			Name:       a.InvolvedName,
+			Namespace:  a.Namespace,
+			UID:        types.UID(a.InvolvedUID),
+		},
+		Type:                a.Type,
+		Reason:              a.Reason,
+		Message:             a.Message,
+		Source:              corev1.EventSource{Component: a.Source},
+		ReportingController: a.Source,
+		Count:               a.Count,
+		FirstTimestamp:      metav1.NewTime(a.FirstTimestamp),
+		LastTimestamp:       metav1.NewTime(a.LastTimestamp),
+	}
+}
+
 func (h *EventHandler) registerCustomRoutes(group *gin.RouterGroup) {
 	group.GET("/resources", h.ListResourceEvents)
+	group.GET("/:namespace/stream", h.StreamEvents)
 }
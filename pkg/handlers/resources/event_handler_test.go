@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListResourceEventsMergesArchivedNodeEvents(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.ArchivedEvent{}))
+	oldDB := model.DB
+	model.DB = db
+	t.Cleanup(func() { model.DB = oldDB })
+
+	now := time.Now().Truncate(time.Second)
+	live := corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "node-1.live", Namespace: "default", UID: "live-uid"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Node", APIVersion: "v1", Name: "node-1"},
+		Type:           corev1.EventTypeNormal,
+		Reason:         "NodeReady",
+		Count:          1,
+		LastTimestamp:  metav1.NewTime(now),
+	}
+	// Node events are stored in the default namespace, the node itself has none
+	require.NoError(t, model.UpsertArchivedEvents([]model.ArchivedEvent{
+		{
+			ClusterName: "test", UID: "live-uid", Namespace: "default",
+			InvolvedKind: "Node", InvolvedAPIVersion: "v1", InvolvedName: "node-1",
+			Reason: "NodeReady", Count: 1, LastTimestamp: now,
+		},
+		{
+			ClusterName: "test", UID: "archived-uid", Namespace: "default",
+			InvolvedKind: "Node", InvolvedAPIVersion: "v1", InvolvedName: "node-1",
+			Type: corev1.EventTypeWarning, Reason: "NodeNotReady", Count: 3,
+			FirstTimestamp: now.Add(-9 * time.Hour), LastTimestamp: now.Add(-8 * time.Hour),
+		},
+		{
+			ClusterName: "test", UID: "other-uid", Namespace: "default",
+			InvolvedKind: "Node", InvolvedAPIVersion: "v1", InvolvedName: "node-2",
+			Reason: "NodeNotReady", Count: 1, LastTimestamp: now,
+		},
+	}))
+
+	server := newTestAPIServer(t, map[string]interface{}{
+		"/api/v1/events": &corev1.EventList{Items: []corev1.Event{live}},
+	})
+	cs := newTestClientSet(t, server, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	r := newTestRouter(cs)
+	r.GET("/events/resources", NewEventHandler().ListResourceEvents)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/resources?resource=nodes&name=node-1", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var events corev1.EventList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+	require.Len(t, events.Items, 2)
+	assert.Equal(t, "live-uid", string(events.Items[0].UID))
+	assert.Empty(t, events.Items[0].Annotations[common.ArchivedEventAnnotation])
+	assert.Equal(t, "archived-uid", string(events.Items[1].UID))
+	assert.Equal(t, "NodeNotReady", events.Items[1].Reason)
+	assert.Equal(t, int32(3), events.Items[1].Count)
+	assert.Equal(t, "true", events.Items[1].Annotations[common.ArchivedEventAnnotation])
+}
@@ -0,0 +1,238 @@
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	"github.com/xhilmi/kubedash/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// eventAggregateTTL drops aggregates not seen for this long, the default event TTL of the API server
+const eventAggregateTTL = time.Hour
+
+// EventAggregate groups the events of one involved object and reason
+type EventAggregate struct {
+	Key            string                 `json:"key"`
+	Namespace      string                 `json:"namespace"`
+	InvolvedObject corev1.ObjectReference `json:"involvedObject"`
+	Reason         string                 `json:"reason"`
+	Type           string                 `json:"type"`
+	Message        string                 `json:"message"`
+	Source         string                 `json:"source,omitempty"`
+	Count          int32                  `json:"count"`
+	FirstSeen      metav1.Time            `json:"firstSeen"`
+	LastSeen       metav1.Time            `json:"lastSeen"`
+
+	// counts keeps the count of each event object, the API server already
+	// deduplicates identical events into one object with a count
+	counts map[types.UID]int32
+}
+
+// eventAggregator aggregates events by involved object and reason
+type eventAggregator struct {
+	aggregates map[string]*EventAggregate
+}
+
+func newEventAggregator() *eventAggregator {
+	return &eventAggregator{aggregates: make(map[string]*EventAggregate)}
+}
+
+func eventAggregateKey(e *corev1.Event) string {
+	obj := e.InvolvedObject
+	return fmt.Sprintf("%s/%s/%s/%s", e.Namespace, obj.Kind, obj.Name, e.Reason)
+}
+
+// add merges an event into its aggregate and returns it, created reports a new aggregate
+func (a *eventAggregator) add(e *corev1.Event) (agg *EventAggregate, created bool) {
+	key := eventAggregateKey(e)
+	agg, ok := a.aggregates[key]
+	if !ok {
+		agg = &EventAggregate{
+			Key:            key,
+			Namespace:      e.Namespace,
+			InvolvedObject: e.InvolvedObject,
+			Reason:         e.Reason,
+			counts:         make(map[types.UID]int32),
+		}
+		a.aggregates[key] = agg
+	}
+
+	first, last := utils.EventTimes(e)
+	if agg.FirstSeen.IsZero() || first.Before(agg.FirstSeen.Time) {
+		agg.FirstSeen = metav1.NewTime(first)
+	}
+	if !last.Before(agg.LastSeen.Time) {
+		// The latest event describes the current state
+		agg.LastSeen = metav1.NewTime(last)
+		agg.Type = e.Type
+		agg.Message = e.Message
+		agg.InvolvedObject = e.InvolvedObject
+		agg.Source = e.Source.Component
+		if agg.Source == "" {
+			agg.Source = e.ReportingController
+		}
+	}
+
+	agg.Count -= agg.counts[e.UID]
+	agg.counts[e.UID] = utils.EventCount(e)
+	agg.Count += agg.counts[e.UID]
+	return agg, !ok
+}
+
+// prune removes the aggregates last seen before the cutoff and returns them
+func (a *eventAggregator) prune(cutoff time.Time) []*EventAggregate {
+	var pruned []*EventAggregate
+	for key, agg := range a.aggregates {
+		if agg.LastSeen.Time.Before(cutoff) {
+			delete(a.aggregates, key)
+			pruned = append(pruned, agg)
+		}
+	}
+	return pruned
+}
+
+// list returns the aggregates, the most recently seen first
+func (a *eventAggregator) list() []*EventAggregate {
+	items := make([]*EventAggregate, 0, len(a.aggregates))
+	for _, agg := range a.aggregates {
+		items = append(items, agg)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].LastSeen.Equal(&items[j].LastSeen) {
+			return items[j].LastSeen.Before(&items[i].LastSeen)
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+// eventStreamSelector builds the field selector of the type, reason and kind query parameters
+func eventStreamSelector(c *gin.Context) (fields.Selector, error) {
+	set := fields.Set{}
+	if eventType := c.Query("type"); eventType != "" {
+		if eventType != corev1.EventTypeWarning && eventType != corev1.EventTypeNormal {
+			return nil, fmt.Errorf("invalid type %q, must be Warning or Normal", eventType)
+		}
+		set["type"] = eventType
+	}
+	if reason := c.Query("reason"); reason != "" {
+		set["reason"] = reason
+	}
+	if kind := c.Query("kind"); kind != "" {
+		set["involvedObject.kind"] = kind
+	}
+	return set.AsSelector(), nil
+}
+
+// StreamEvents handles GET /events/:namespace/stream?type=&reason=&kind=, namespace may be _all.
+// It streams events aggregated by involved object and reason over SSE: a "snapshot" of all
+// aggregates, the most recently seen first, then "added" and "modified" for changed aggregates
+// and "deleted" for aggregates not seen for an hour.
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
+	ctx := c.Request.Context()
+
+	namespace := c.Param("namespace")
+	if !rbac.CanAccess(user, "events", string(common.VerbWatch), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbWatch), "events", namespace, cs.Name),
+		})
+		return
+	}
+	selector, err := eventStreamSelector(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	listNamespace := namespace
+	if namespace == "_all" {
+		listNamespace = metav1.NamespaceAll
+	}
+
+	events := cs.K8sClient.ClientSet.CoreV1().Events(listNamespace)
+	list, err := events.List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list events: " + err.Error()})
+		return
+	}
+	watcher, err := events.Watch(ctx, metav1.ListOptions{
+		FieldSelector:   selector.String(),
+		ResourceVersion: list.ResourceVersion,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to start watch: %v", err)})
+		return
+	}
+	defer watcher.Stop()
+
+	aggregator := newEventAggregator()
+	for i := range list.Items {
+		if h.visible(c, namespace, &list.Items[i]) {
+			aggregator.add(&list.Items[i])
+		}
+	}
+	aggregator.prune(time.Now().Add(-eventAggregateTTL))
+	if err := writeSSE(c, "snapshot", aggregator.list()); err != nil {
+		return
+	}
+
+	// Keep-alive pings, also used to expire old aggregates
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	flusher, _ := c.Writer.(http.Flusher)
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = writeSSE(c, "close", gin.H{"message": "connection closed"})
+			return
+		case <-ticker.C:
+			for _, agg := range aggregator.prune(time.Now().Add(-eventAggregateTTL)) {
+				_ = writeSSE(c, "deleted", agg)
+			}
+			_, _ = fmt.Fprintf(c.Writer, ": ping\n\n") // comment line per SSE
+			flusher.Flush()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				_ = writeSSE(c, "close", gin.H{"message": "watch channel closed"})
+				return
+			}
+			if event.Type == watch.Error {
+				msg := "watch error"
+				if status, ok := event.Object.(*metav1.Status); ok && status.Message != "" {
+					msg = status.Message
+				}
+				_ = writeSSE(c, "error", gin.H{"error": msg})
+				continue
+			}
+			// Deleted events expired in the API server, their occurrences stay counted
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+			e, ok := event.Object.(*corev1.Event)
+			if !ok || !h.visible(c, namespace, e) {
+				continue
+			}
+			agg, created := aggregator.add(e)
+			if created {
+				_ = writeSSE(c, "added", agg)
+			} else {
+				_ = writeSSE(c, "modified", agg)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 	"math"
 	"net/http"
 	"reflect"
@@ -21,7 +22,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -78,17 +78,19 @@ func (h *GenericResourceHandler[T, V]) getGroupKind() schema.GroupKind {
 }
 
 func (h *GenericResourceHandler[T, V]) recordHistory(c *gin.Context, opType string, prev, curr T, success bool, errMsg string) {
+	recordResourceHistory(c, h.name, curr.GetNamespace(), curr.GetName(), opType, h.ToYAML(prev), h.ToYAML(curr), success, errMsg)
+}
+
+// recordResourceHistory stores a create/update/patch entry in resource history
+func recordResourceHistory(c *gin.Context, resourceType, namespace, name, opType, prevYAML, currYAML string, success bool, errMsg string) {
 	cs := c.MustGet("cluster").(*cluster.ClientSet)
 	user := c.MustGet("user").(model.User)
 
-	prevYAML := h.ToYAML(prev)
-	currYAML := h.ToYAML(curr)
-	
 	// For CREATE operations, store full YAML since there's no previous version
 	// For UPDATE/EDIT operations, store only the diff to save disk space
 	var resourceYAML, diffPatch string
 	isCreateOp := opType == "create" || opType == "apply" || prevYAML == ""
-	
+
 	if isCreateOp {
 		// First time creation - store full YAML
 		resourceYAML = currYAML
@@ -101,9 +103,9 @@ func (h *GenericResourceHandler[T, V]) recordHistory(c *gin.Context, opType stri
 
 	history := model.ResourceHistory{
 		ClusterName:   cs.Name,
-		ResourceType:  h.name,
-		ResourceName:  curr.GetName(),
-		Namespace:     curr.GetNamespace(),
+		ResourceType:  resourceType,
+		ResourceName:  name,
+		Namespace:     namespace,
 		OperationType: opType,
 		YAMLDiff:      diffPatch,
 		ResourceYAML:  resourceYAML,
@@ -117,6 +119,51 @@ func (h *GenericResourceHandler[T, V]) recordHistory(c *gin.Context, opType stri
 	}
 }
 
+// recordActionHistory records a successful action (restart, scale, drain...) together with its details
+func (h *GenericResourceHandler[T, V]) recordActionHistory(c *gin.Context, namespace, name, actionType string, details map[string]interface{}) {
+	h.recordActionResult(c, namespace, name, actionType, details, nil)
+}
+
+// recordActionResult records an action and its outcome together with the current object as YAML
+func (h *GenericResourceHandler[T, V]) recordActionResult(c *gin.Context, namespace, name, actionType string, details map[string]interface{}, actionErr error) {
+	// Get current object state for YAML
+	resourceYAML := ""
+	if obj, err := h.GetResource(c, namespace, name); err == nil {
+		resourceYAML = h.ToYAML(obj.(T))
+	}
+	createActionHistory(c, h.name, namespace, name, actionType, resourceYAML, details, actionErr)
+}
+
+// createActionHistory stores an action entry in resource history.
+// The action details are kept in the PreviousYAML field.
+func createActionHistory(c *gin.Context, resourceType, namespace, name, actionType, resourceYAML string, details map[string]interface{}, actionErr error) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
+
+	// Build details string
+	detailsJSON, _ := json.Marshal(details)
+
+	errMsg := ""
+	if actionErr != nil {
+		errMsg = actionErr.Error()
+	}
+	history := model.ResourceHistory{
+		ClusterName:   cs.Name,
+		ResourceType:  resourceType,
+		ResourceName:  name,
+		Namespace:     namespace,
+		OperationType: actionType,
+		ResourceYAML:  resourceYAML,
+		PreviousYAML:  string(detailsJSON), // Store action details in PreviousYAML field
+		Success:       actionErr == nil,
+		ErrorMessage:  errMsg,
+		OperatorID:    user.ID,
+	}
+	if err := model.DB.Create(&history).Error; err != nil {
+		klog.Errorf("Failed to create resource history: %v", err)
+	}
+}
+
 func (h *GenericResourceHandler[T, V]) IsClusterScoped() bool {
 	return h.isClusterScoped
 }
@@ -196,31 +243,12 @@ func (h *GenericResourceHandler[T, V]) list(c *gin.Context) (V, error) {
 		listOpts = append(listOpts, client.Continue(continueToken))
 	}
 
-	// Add label selector support
-	if c.Query("labelSelector") != "" {
-		labelSelector := c.Query("labelSelector")
-		selector, err := metav1.ParseToLabelSelector(labelSelector)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid labelSelector parameter: " + err.Error()})
-			return zero, err
-		}
-		labelSelectorOption, err := metav1.LabelSelectorAsSelector(selector)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to convert labelSelector: " + err.Error()})
-			return zero, err
-		}
-		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: labelSelectorOption})
-	}
-
-	if c.Query("fieldSelector") != "" {
-		fieldSelector := c.Query("fieldSelector")
-		fieldSelectorOption, err := fields.ParseSelector(fieldSelector)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid fieldSelector parameter: " + err.Error()})
-			return zero, err
-		}
-		listOpts = append(listOpts, client.MatchingFieldsSelector{Selector: fieldSelectorOption})
+	selectorOpts, err := parseSelectors(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return zero, err
 	}
+	listOpts = append(listOpts, selectorOpts...)
 
 	if err := cs.K8sClient.List(ctx, objectList, listOpts...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -251,7 +279,6 @@ func (h *GenericResourceHandler[T, V]) list(c *gin.Context) (V, error) {
 		return t1.After(t2.Time)
 	})
 
-	user := c.MustGet("user").(model.User)
 	filterItems := make([]runtime.Object, 0, len(items))
 	for i := range items {
 		obj, err := meta.Accessor(items[i])
@@ -259,16 +286,8 @@ func (h *GenericResourceHandler[T, V]) list(c *gin.Context) (V, error) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to access object metadata"})
 			return zero, err
 		}
-		obj.SetManagedFields(nil)
-		anno := obj.GetAnnotations()
-		if anno != nil {
-			delete(anno, common.KubectlAnnotation)
-		}
-		// for namespaces, we need to ensure user has permission to view them
-		if h.Name() == "namespaces" && !rbac.CanAccessNamespace(user, cs.Name, obj.GetName()) {
-			continue
-		}
-		if namespace == "_all" && obj.GetNamespace() != "" && !rbac.CanAccessNamespace(user, cs.Name, obj.GetNamespace()) {
+		trimObjectMeta(obj)
+		if !h.visible(c, namespace, obj) {
 			continue
 		}
 		filterItems = append(filterItems, items[i])
@@ -278,7 +297,26 @@ func (h *GenericResourceHandler[T, V]) list(c *gin.Context) (V, error) {
 	return objectList, nil
 }
 
+// visible reports whether the current user may see obj when listing or watching
+// with the given namespace parameter
+func (h *GenericResourceHandler[T, V]) visible(c *gin.Context, namespace string, obj metav1.Object) bool {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
+	// for namespaces, we need to ensure user has permission to view them
+	if h.Name() == "namespaces" && !rbac.CanAccessNamespace(user, cs.Name, obj.GetName()) {
+		return false
+	}
+	if namespace == "_all" && obj.GetNamespace() != "" && !rbac.CanAccessNamespace(user, cs.Name, obj.GetNamespace()) {
+		return false
+	}
+	return true
+}
+
 func (h *GenericResourceHandler[T, V]) List(c *gin.Context) {
+	if wantsTable(c) {
+		h.listTable(c)
+		return
+	}
 	object, err := h.list(c)
 	if err != nil {
 		return
@@ -528,6 +566,11 @@ func (h *GenericResourceHandler[T, V]) Search(c *gin.Context, q string, limit in
 func (h *GenericResourceHandler[T, V]) registerCustomRoutes(group *gin.RouterGroup) {}
 
 func (h *GenericResourceHandler[T, V]) ListHistory(c *gin.Context) {
+	listResourceHistory(c, h.name)
+}
+
+// listResourceHistory returns a page of history records of the object addressed by the route
+func listResourceHistory(c *gin.Context, resourceType string) {
 	cs := c.MustGet("cluster").(*cluster.ClientSet)
 	namespace := c.Param("namespace")
 	resourceName := c.Param("name")
@@ -544,7 +587,7 @@ func (h *GenericResourceHandler[T, V]) ListHistory(c *gin.Context) {
 
 	// Get total count
 	var total int64
-	if err := model.DB.Model(&model.ResourceHistory{}).Where("cluster_name = ? AND resource_type = ? AND resource_name = ? AND namespace = ?", cs.Name, h.name, resourceName, namespace).Count(&total).Error; err != nil {
+	if err := model.DB.Model(&model.ResourceHistory{}).Where("cluster_name = ? AND resource_type = ? AND resource_name = ? AND namespace = ?", cs.Name, resourceType, resourceName, namespace).Count(&total).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -553,7 +596,7 @@ func (h *GenericResourceHandler[T, V]) ListHistory(c *gin.Context) {
 	var historyRecords []model.ResourceHistory
 	if err := model.DB.Preload("Operator").
 		Select("id, sequence_id, cluster_name, resource_type, resource_name, namespace, operation_type, success, error_message, operator_id, created_at, updated_at").
-		Where("cluster_name = ? AND resource_type = ? AND resource_name = ? AND namespace = ?", cs.Name, h.name, resourceName, namespace).
+		Where("cluster_name = ? AND resource_type = ? AND resource_name = ? AND namespace = ?", cs.Name, resourceType, resourceName, namespace).
 		Order("created_at DESC").
 		Offset((page - 1) * pageSize).
 		Limit(pageSize).
@@ -621,6 +664,10 @@ func (h *GenericResourceHandler[T, V]) ListHistory(c *gin.Context) {
 // GetHistoryDetail returns full YAML content for a specific history record
 // This reconstructs the YAML from diffs if needed
 func (h *GenericResourceHandler[T, V]) GetHistoryDetail(c *gin.Context) {
+	getResourceHistoryDetail(c)
+}
+
+func getResourceHistoryDetail(c *gin.Context) {
 	cs := c.MustGet("cluster").(*cluster.ClientSet)
 	historyID := c.Param("historyId")
 	
@@ -665,7 +712,7 @@ func (h *GenericResourceHandler[T, V]) GetHistoryDetail(c *gin.Context) {
 
 		if err == nil {
 			// Found previous record - reconstruct its YAML first
-			previousYAML = h.reconstructYAML(&prevHistory)
+			previousYAML = reconstructHistoryYAML(&prevHistory)
 			// Apply current diff to get current YAML
 			currentYAML = utils.ApplyDiff(previousYAML, history.YAMLDiff)
 		} else {
@@ -692,8 +739,8 @@ func (h *GenericResourceHandler[T, V]) GetHistoryDetail(c *gin.Context) {
 	})
 }
 
-// reconstructYAML recursively reconstructs YAML from diff chain
-func (h *GenericResourceHandler[T, V]) reconstructYAML(history *model.ResourceHistory) string {
+// reconstructHistoryYAML recursively reconstructs YAML from diff chain
+func reconstructHistoryYAML(history *model.ResourceHistory) string {
 	// If full YAML is stored (CREATE operation), return it
 	if history.ResourceYAML != "" {
 		return history.ResourceYAML
@@ -708,7 +755,7 @@ func (h *GenericResourceHandler[T, V]) reconstructYAML(history *model.ResourceHi
 			First(&prevHistory).Error
 
 		if err == nil {
-			prevYAML := h.reconstructYAML(&prevHistory)
+			prevYAML := reconstructHistoryYAML(&prevHistory)
 			return utils.ApplyDiff(prevYAML, history.YAMLDiff)
 		}
 	}
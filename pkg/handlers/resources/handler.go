@@ -1,11 +1,13 @@
 package resources
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
 	"github.com/xhilmi/kubedash/pkg/common"
-	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -14,7 +16,9 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
 	metricsv1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
@@ -37,12 +41,39 @@ type resourceHandler interface {
 	GetHistoryDetail(c *gin.Context) // New method to get full YAML for a history record
 
 	Describe(c *gin.Context)
+	Watch(c *gin.Context)
 }
 
 type Restartable interface {
 	Restart(c *gin.Context, namespace, name string) error
 }
 
+// restartPodTemplate triggers a rolling restart the way `kubectl rollout restart` does,
+// by bumping the restartedAt annotation on the pod template.
+// A strategic merge patch is used so only the annotation is touched.
+func restartPodTemplate(c *gin.Context, obj client.Object) error {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						"kite.kubernetes.io/restartedAt": time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch: %w", err)
+	}
+
+	return cs.K8sClient.Patch(c.Request.Context(), obj, client.RawPatch(types.StrategicMergePatchType, patchBytes))
+}
+
 var handlers = map[string]resourceHandler{}
 
 func RegisterRoutes(group *gin.RouterGroup) {
@@ -61,11 +92,11 @@ func RegisterRoutes(group *gin.RouterGroup) {
 		"crds":                     NewGenericResourceHandler[*apiextensionsv1.CustomResourceDefinition, *apiextensionsv1.CustomResourceDefinitionList]("crds", true, false),
 		"events":                   NewEventHandler(),
 		"deployments":              NewDeploymentHandler(),
-		"replicasets":              NewGenericResourceHandler[*appsv1.ReplicaSet, *appsv1.ReplicaSetList]("replicasets", false, false),
-		"statefulsets":             NewGenericResourceHandler[*appsv1.StatefulSet, *appsv1.StatefulSetList]("statefulsets", false, false),
-		"daemonsets":               NewGenericResourceHandler[*appsv1.DaemonSet, *appsv1.DaemonSetList]("daemonsets", false, true),
+		"replicasets":              NewReplicaSetHandler(),
+		"statefulsets":             NewStatefulSetHandler(),
+		"daemonsets":               NewDaemonSetHandler(),
 		"jobs":                     NewGenericResourceHandler[*batchv1.Job, *batchv1.JobList]("jobs", false, false),
-		"cronjobs":                 NewGenericResourceHandler[*batchv1.CronJob, *batchv1.CronJobList]("cronjobs", false, false),
+		"cronjobs":                 NewCronJobHandler(),
 		"ingresses":                NewGenericResourceHandler[*networkingv1.Ingress, *networkingv1.IngressList]("ingresses", false, false),
 		"storageclasses":           NewGenericResourceHandler[*storagev1.StorageClass, *storagev1.StorageClassList]("storageclasses", true, false),
 		"roles":                    NewGenericResourceHandler[*rbacv1.Role, *rbacv1.RoleList]("roles", false, false),
@@ -111,18 +142,37 @@ func RegisterRoutes(group *gin.RouterGroup) {
 	{
 		otherGroup.GET("", crHandler.List)
 		otherGroup.GET("/_all", crHandler.List)
+		otherGroup.GET("/_all/watch", crHandler.Watch)
 		otherGroup.GET("/_all/:name", crHandler.Get)
 		otherGroup.GET("/_all/:name/describe", crHandler.Describe)
+		otherGroup.GET("/_all/:name/history", crHandler.ListHistory)
+		otherGroup.GET("/_all/:name/history/:historyId", crHandler.GetHistoryDetail)
+		otherGroup.POST("/_all", crHandler.Create)
 		otherGroup.PUT("/_all/:name", crHandler.Update)
+		otherGroup.PATCH("/_all/:name", crHandler.Patch)
+		otherGroup.POST("/_all/:name/scale", crHandler.Scale)
 		otherGroup.DELETE("/_all/:name", crHandler.Delete)
 
 		otherGroup.GET("/:namespace", crHandler.List)
+		otherGroup.GET("/:namespace/watch", crHandler.Watch)
 		otherGroup.GET("/:namespace/:name", crHandler.Get)
 		otherGroup.GET("/:namespace/:name/describe", crHandler.Describe)
+		otherGroup.GET("/:namespace/:name/history", crHandler.ListHistory)
+		otherGroup.GET("/:namespace/:name/history/:historyId", crHandler.GetHistoryDetail)
+		otherGroup.POST("/:namespace", crHandler.Create)
 		otherGroup.PUT("/:namespace/:name", crHandler.Update)
+		otherGroup.PATCH("/:namespace/:name", crHandler.Patch)
+		otherGroup.POST("/:namespace/:name/scale", crHandler.Scale)
 		otherGroup.DELETE("/:namespace/:name", crHandler.Delete)
 	}
 
+	// Custom resources are only searched for CRDs opted in through SEARCH_CRDS
+	for _, crdName := range common.SearchCRDs {
+		if _, exists := SearchFuncs[crdName]; !exists {
+			RegisterSearchFunc(crdName, crHandler.searchFunc(crdName))
+		}
+	}
+
 	// Register FluxCD HelmRelease custom routes
 	helmReleaseHandler := NewHelmReleaseHandler()
 	helmReleaseGroup := group.Group("/helmreleases.helm.toolkit.fluxcd.io")
@@ -132,6 +182,7 @@ func RegisterRoutes(group *gin.RouterGroup) {
 func registerClusterScopeRoutes(group *gin.RouterGroup, handler resourceHandler) {
 	group.GET("", handler.List)
 	group.GET("/_all", handler.List)
+	group.GET("/_all/watch", handler.Watch)
 	group.GET("/_all/:name", handler.Get)
 	group.POST("/_all", handler.Create)
 	group.PUT("/_all/:name", handler.Update)
@@ -145,6 +196,7 @@ func registerClusterScopeRoutes(group *gin.RouterGroup, handler resourceHandler)
 func registerNamespaceScopeRoutes(group *gin.RouterGroup, handler resourceHandler) {
 	group.GET("", handler.List)
 	group.GET("/:namespace", handler.List)
+	group.GET("/:namespace/watch", handler.Watch)
 	group.GET("/:namespace/:name", handler.Get)
 	group.POST("/:namespace", handler.Create)
 	group.PUT("/:namespace/:name", handler.Update)
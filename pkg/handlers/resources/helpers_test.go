@@ -0,0 +1,99 @@
+package resources
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// testAPIResources is the core API discovery served by newTestAPIServer
+var testAPIResources = metav1.APIResourceList{
+	GroupVersion: "v1",
+	APIResources: []metav1.APIResource{
+		{Name: "pods", SingularName: "pod", Namespaced: true, Kind: "Pod", Verbs: []string{"get", "list", "watch"}},
+		{Name: "nodes", SingularName: "node", Namespaced: false, Kind: "Node", Verbs: []string{"get", "list", "watch"}},
+		{Name: "events", SingularName: "event", Namespaced: true, Kind: "Event", Verbs: []string{"get", "list", "watch"}},
+	},
+}
+
+// newTestAPIServer serves core API discovery and the given responses by path
+func newTestAPIServer(t *testing.T, responses map[string]interface{}) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, metav1.APIVersions{Versions: []string{"v1"}})
+	})
+	mux.HandleFunc("/apis", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, metav1.APIGroupList{})
+	})
+	mux.HandleFunc("/api/v1", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, testAPIResources)
+	})
+	for path, response := range responses {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, response)
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestClientSet returns a cluster client talking to the test API server,
+// objects are served by a fake controller-runtime client
+func newTestClientSet(t *testing.T, server *httptest.Server, objects ...client.Object) *cluster.ClientSet {
+	t.Helper()
+	config := &rest.Config{Host: server.URL}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(kube.GetScheme()).WithObjects(objects...).Build()
+	return &cluster.ClientSet{
+		Name: "test",
+		K8sClient: &kube.K8sClient{
+			Client:        fakeClient,
+			ClientSet:     clientset,
+			Configuration: config,
+			WatchClient:   fakeClient,
+			Mapper:        kube.NewRESTMapper(clientset.Discovery()),
+		},
+	}
+}
+
+// newTestRouter injects the cluster and an admin user, like the auth and cluster middlewares
+func newTestRouter(cs *cluster.ClientSet) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("cluster", cs)
+		c.Set("user", model.User{
+			Username: "admin",
+			Roles: []common.Role{{
+				Name:       "admin",
+				Clusters:   []string{"*"},
+				Namespaces: []string{"*"},
+				Resources:  []string{"*"},
+				Verbs:      []string{"*"},
+			}},
+		})
+		c.Next()
+	})
+	return r
+}
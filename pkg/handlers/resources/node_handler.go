@@ -3,8 +3,12 @@ package resources
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/samber/lo"
@@ -14,7 +18,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/drain"
 	metricsv1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
@@ -32,17 +38,145 @@ func NewNodeHandler() *NodeHandler {
 	}
 }
 
-// DrainNode drains a node by evicting all pods
+// drainPodResult is the outcome of evicting or deleting a single pod during a drain
+type drainPodResult struct {
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+	Status        string `json:"status"` // pending, evicting, evicted, failed
+	UsingEviction bool   `json:"usingEviction"`
+	Error         string `json:"error,omitempty"`
+}
+
+// drainProgress collects per-pod drain results and, in streaming mode, relays them over SSE.
+// The drain helper invokes its callbacks from one goroutine per pod, so every access is guarded.
+// Evictions may still finish after the drain timed out, closed stops relaying them once the
+// handler returned and the gin context is reused.
+type drainProgress struct {
+	mu       sync.Mutex
+	c        *gin.Context
+	stream   bool
+	closed   bool
+	pods     []*drainPodResult
+	index    map[string]*drainPodResult
+	messages []string
+}
+
+func newDrainProgress(c *gin.Context, stream bool) *drainProgress {
+	return &drainProgress{c: c, stream: stream, index: map[string]*drainPodResult{}}
+}
+
+func (p *drainProgress) send(event string, payload any) {
+	if !p.stream || p.closed {
+		return
+	}
+	_ = writeSSE(p.c, event, payload)
+}
+
+// close stops sending events, callbacks arriving later only update the results
+func (p *drainProgress) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+}
+
+// snapshot copies the pod results and messages so they can be used outside the lock
+func (p *drainProgress) snapshot() ([]drainPodResult, []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pods := make([]drainPodResult, 0, len(p.pods))
+	for _, result := range p.pods {
+		pods = append(pods, *result)
+	}
+	return pods, append([]string{}, p.messages...)
+}
+
+func (p *drainProgress) emit(event string, payload any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.send(event, payload)
+}
+
+func (p *drainProgress) setPods(pods []corev1.Pod) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pod := range pods {
+		result := &drainPodResult{Namespace: pod.Namespace, Name: pod.Name, Status: "pending"}
+		p.pods = append(p.pods, result)
+		p.index[pod.Namespace+"/"+pod.Name] = result
+	}
+	p.send("pods", p.pods)
+}
+
+func (p *drainProgress) podStarted(pod *corev1.Pod, usingEviction bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result, ok := p.index[pod.Namespace+"/"+pod.Name]
+	if !ok {
+		return
+	}
+	result.Status = "evicting"
+	result.UsingEviction = usingEviction
+	p.send("evicting", result)
+}
+
+func (p *drainProgress) podFinished(pod *corev1.Pod, usingEviction bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result, ok := p.index[pod.Namespace+"/"+pod.Name]
+	if !ok {
+		return
+	}
+	result.UsingEviction = usingEviction
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		p.send("failed", result)
+		return
+	}
+	result.Status = "evicted"
+	p.send("evicted", result)
+}
+
+// writer returns an io.Writer that forwards drain helper output line by line as the given event.
+// Eviction retries caused by PodDisruptionBudgets (HTTP 429) are reported this way.
+func (p *drainProgress) writer(event string) io.Writer {
+	return drainLogWriter{p: p, event: event}
+}
+
+type drainLogWriter struct {
+	p     *drainProgress
+	event string
+}
+
+func (w drainLogWriter) Write(b []byte) (int, error) {
+	w.p.mu.Lock()
+	defer w.p.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if line == "" {
+			continue
+		}
+		w.p.messages = append(w.p.messages, line)
+		w.p.send(w.event, gin.H{"message": line})
+	}
+	return len(b), nil
+}
+
+// DrainNode drains a node: it cordons the node and then evicts its pods through the Eviction API,
+// so PodDisruptionBudgets are respected and evictions blocked by a budget are retried.
+// With ?stream=true the progress of every pod is streamed back as server-sent events.
 func (h *NodeHandler) DrainNode(c *gin.Context) {
 	nodeName := c.Param("name")
 	ctx := c.Request.Context()
 	cs := c.MustGet("cluster").(*cluster.ClientSet)
 	// Parse the request body for drain options
 	var drainRequest struct {
-		Force            bool `json:"force" binding:"required"`
-		GracePeriod      int  `json:"gracePeriod" binding:"min=0"`
+		Force bool `json:"force"`
+		// GracePeriod overrides the pods' termination grace period, the pod's own value is used when omitted
+		GracePeriod      *int `json:"gracePeriod" binding:"omitempty,min=0"`
 		DeleteLocal      bool `json:"deleteLocalData"`
 		IgnoreDaemonsets bool `json:"ignoreDaemonsets"`
+		// TimeoutSeconds bounds the whole drain, defaults to 5 minutes
+		TimeoutSeconds int `json:"timeoutSeconds" binding:"min=0"`
 	}
 
 	if err := c.ShouldBindJSON(&drainRequest); err != nil {
@@ -61,18 +195,97 @@ func (h *NodeHandler) DrainNode(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement actual drain logic
-	// For now, we'll simulate the drain operation
-	// In a real implementation, you would:
-	// 1. Mark the node as unschedulable (cordon)
-	// 2. Evict all pods from the node
-	// 3. Handle daemonsets appropriately
-	// 4. Wait for pods to be evicted or force delete them
+	gracePeriod := -1
+	if drainRequest.GracePeriod != nil {
+		gracePeriod = *drainRequest.GracePeriod
+	}
+	timeout := 5 * time.Minute
+	if drainRequest.TimeoutSeconds > 0 {
+		timeout = time.Duration(drainRequest.TimeoutSeconds) * time.Second
+	}
+
+	stream := c.Query("stream") == "true"
+	progress := newDrainProgress(c, stream)
+	defer progress.close()
+	helper := &drain.Helper{
+		Ctx:                             ctx,
+		Client:                          cs.K8sClient.ClientSet,
+		Force:                           drainRequest.Force,
+		GracePeriodSeconds:              gracePeriod,
+		IgnoreAllDaemonSets:             drainRequest.IgnoreDaemonsets,
+		DeleteEmptyDirData:              drainRequest.DeleteLocal,
+		Timeout:                         timeout,
+		Out:                             progress.writer("progress"),
+		ErrOut:                          progress.writer("warning"),
+		OnPodDeletionOrEvictionStarted:  progress.podStarted,
+		OnPodDeletionOrEvictionFinished: progress.podFinished,
+	}
+
+	details := map[string]interface{}{
+		"action":           "drain",
+		"force":            drainRequest.Force,
+		"gracePeriod":      gracePeriod,
+		"deleteLocalData":  drainRequest.DeleteLocal,
+		"ignoreDaemonsets": drainRequest.IgnoreDaemonsets,
+	}
+	fail := func(status int, err error) {
+		pods, messages := progress.snapshot()
+		details["pods"] = pods
+		h.recordActionResult(c, "", nodeName, "drain", details, err)
+		klog.Errorf("Failed to drain node %s: %v", nodeName, err)
+		if stream {
+			progress.emit("error", gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(status, gin.H{
+			"error":    err.Error(),
+			"node":     nodeName,
+			"pods":     pods,
+			"messages": messages,
+		})
+	}
+
+	// 1. Cordon the node so no new pods land on it
+	if err := h.markNodeSchedulable(ctx, cs.K8sClient, nodeName, false); err != nil {
+		fail(http.StatusInternalServerError, fmt.Errorf("failed to cordon node: %w", err))
+		return
+	}
+	progress.emit("cordoned", gin.H{"node": nodeName})
+
+	// 2. Collect the pods to evict, daemonset, mirror and local storage pods are filtered by the options
+	list, errs := helper.GetPodsForDeletion(nodeName)
+	if len(errs) > 0 {
+		fail(http.StatusConflict, fmt.Errorf("cannot drain node: %w", utilerrors.NewAggregate(errs)))
+		return
+	}
+	if warnings := list.Warnings(); warnings != "" {
+		_, _ = progress.writer("warning").Write([]byte(warnings))
+	}
+	pods := list.Pods()
+	progress.setPods(pods)
+
+	// 3. Evict the pods and wait for them to terminate
+	if err := helper.DeleteOrEvictPods(pods); err != nil {
+		fail(http.StatusInternalServerError, err)
+		return
+	}
+
+	results, messages := progress.snapshot()
+	details["pods"] = results
+	h.recordActionHistory(c, "", nodeName, "drain", details)
+	klog.Infof("Node %s drained in cluster %s, %d pods evicted", nodeName, cs.Name, len(pods))
 
+	message := fmt.Sprintf("Node %s drained successfully", nodeName)
+	if stream {
+		progress.emit("completed", gin.H{"message": message, "node": nodeName, "pods": results})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("Node %s drain initiated", nodeName),
-		"node":    node.Name,
-		"options": drainRequest,
+		"message":  message,
+		"node":     node.Name,
+		"options":  drainRequest,
+		"pods":     results,
+		"messages": messages,
 	})
 }
 
@@ -249,6 +462,10 @@ func (h *NodeHandler) UntaintNode(c *gin.Context) {
 }
 
 func (h *NodeHandler) List(c *gin.Context) {
+	if wantsTable(c) {
+		h.listTable(c)
+		return
+	}
 	cs := c.MustGet("cluster").(*cluster.ClientSet)
 	var nodeMetrics metricsv1.NodeMetricsList
 
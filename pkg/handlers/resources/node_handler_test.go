@@ -0,0 +1,38 @@
+package resources
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDrainProgressAfterClose(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}}
+
+	progress := newDrainProgress(c, true)
+	progress.setPods([]corev1.Pod{pod})
+	progress.podStarted(&pod, true)
+	pods, messages := progress.snapshot()
+	progress.close()
+	sent := w.Body.String()
+
+	// An eviction finishing after the drain timed out must not write to the reused context
+	progress.podFinished(&pod, true, errors.New("timed out"))
+	_, _ = progress.writer("warning").Write([]byte("evicting pod default/web-1\n"))
+	assert.Equal(t, sent, w.Body.String())
+
+	// Snapshots are not changed by later callbacks
+	assert.Equal(t, "evicting", pods[0].Status)
+	assert.Empty(t, messages)
+
+	pods, messages = progress.snapshot()
+	assert.Equal(t, "failed", pods[0].Status)
+	assert.Equal(t, []string{"evicting pod default/web-1"}, messages)
+}
@@ -121,6 +121,10 @@ func (h *PodHandler) ListMetrics(c *gin.Context) (map[string]metricsv1.PodMetric
 }
 
 func (h *PodHandler) List(c *gin.Context) {
+	if wantsTable(c) {
+		h.listTable(c)
+		return
+	}
 	objlist, err := h.list(c)
 	if err != nil {
 		return
@@ -165,12 +169,6 @@ func (h *PodHandler) List(c *gin.Context) {
 	c.JSON(200, result)
 }
 
-// registerCustomRoutes adds pod-specific extra routes (SSE watch)
-func (h *PodHandler) registerCustomRoutes(group *gin.RouterGroup) {
-	// watch pods in namespace (or _all)
-	group.GET("/:namespace/watch", h.Watch)
-}
-
 // writeSSE writes a single SSE event with the given name and payload
 func writeSSE(c *gin.Context, event string, payload any) error {
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
@@ -199,7 +197,8 @@ func writeSSE(c *gin.Context, event string, payload any) error {
 	return nil
 }
 
-// Watch implements SSE-based watch for pods list with initial snapshot and incremental updates
+// Watch implements SSE-based watch for pods list with initial snapshot and incremental updates.
+// It overrides the generic watch to attach pod metrics to every event.
 func (h *PodHandler) Watch(c *gin.Context) {
 	cs := c.MustGet("cluster").(*cluster.ClientSet)
 
@@ -0,0 +1,24 @@
+package resources
+
+import (
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+type ReplicaSetHandler struct {
+	*GenericResourceHandler[*appsv1.ReplicaSet, *appsv1.ReplicaSetList]
+}
+
+func NewReplicaSetHandler() *ReplicaSetHandler {
+	return &ReplicaSetHandler{
+		GenericResourceHandler: NewGenericResourceHandler[*appsv1.ReplicaSet, *appsv1.ReplicaSetList](
+			"replicasets",
+			false, // ReplicaSets are namespaced resources
+			false,
+		),
+	}
+}
+
+func (h *ReplicaSetHandler) registerCustomRoutes(group *gin.RouterGroup) {
+	group.POST("/:namespace/:name/scale", h.Scale)
+}
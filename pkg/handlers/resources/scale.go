@@ -0,0 +1,197 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// scaleRequest requires replicas, so an empty or misspelled body does not scale to zero
+type scaleRequest struct {
+	Replicas *int32 `json:"replicas" binding:"required,gte=0"`
+}
+
+// scaleSubresource sets the replica count of any scalable object through its /scale subresource
+// and returns the previous replica count
+func scaleSubresource(ctx context.Context, k8sClient *kube.K8sClient, gvk schema.GroupVersionKind, namespace, name string, replicas int32) (int32, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	scale := &unstructured.Unstructured{}
+	scale.SetGroupVersionKind(autoscalingv1.SchemeGroupVersion.WithKind("Scale"))
+	if err := k8sClient.SubResource("scale").Get(ctx, obj, scale); err != nil {
+		return 0, err
+	}
+
+	oldReplicas, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+	if err := unstructured.SetNestedField(scale.Object, int64(replicas), "spec", "replicas"); err != nil {
+		return 0, err
+	}
+	if err := k8sClient.SubResource("scale").Update(ctx, obj, client.WithSubResourceBody(scale)); err != nil {
+		return int32(oldReplicas), err
+	}
+	return int32(oldReplicas), nil
+}
+
+// Scale handles POST /:namespace/:name/scale for any resource that serves the scale subresource.
+// Requires 'scale' verb permission (fine-grained control)
+func (h *GenericResourceHandler[T, V]) Scale(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	if !rbac.CanAccess(user, h.name, string(common.VerbScale), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbScale), h.name, namespace, cs.Name),
+		})
+		return
+	}
+
+	var req scaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	replicas := *req.Replicas
+
+	gvk, err := apiutil.GVKForObject(reflect.New(h.objectType).Interface().(T), cs.K8sClient.Scheme())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	oldReplicas, err := scaleSubresource(c.Request.Context(), cs.K8sClient, gvk, namespace, name, replicas)
+	if err != nil {
+		klog.Errorf("Failed to scale %s %s/%s: %v", h.name, namespace, name, err)
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scale resource: " + err.Error()})
+		return
+	}
+
+	h.recordActionHistory(c, namespace, name, "scale", map[string]interface{}{
+		"action":      "scale",
+		"oldReplicas": oldReplicas,
+		"newReplicas": replicas,
+	})
+
+	klog.Infof("User %s scaled %s %s/%s from %d to %d replicas in cluster %s",
+		user.Key(), h.name, namespace, name, oldReplicas, replicas, cs.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Resource scaled successfully",
+		"oldReplicas": oldReplicas,
+		"newReplicas": replicas,
+	})
+}
+
+// Scale handles POST /:crd/:namespace/:name/scale for custom resources whose CRD declares subresources.scale
+func (h *CRHandler) Scale(c *gin.Context) {
+	crdName := c.Param("crd")
+	name := c.Param("name")
+	namespace := c.Param("namespace")
+
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	ctx := c.Request.Context()
+
+	crd, err := h.getCRDByName(ctx, cs.K8sClient, crdName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "CustomResourceDefinition not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	gvr := h.getGVRFromCRD(crd)
+	scalable := false
+	for _, v := range crd.Spec.Versions {
+		if v.Name == gvr.Version && v.Subresources != nil && v.Subresources.Scale != nil {
+			scalable = true
+			break
+		}
+	}
+	if !scalable {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s does not declare the scale subresource", crdName)})
+		return
+	}
+
+	if crd.Spec.Scope == apiextensionsv1.NamespaceScoped {
+		if namespace == "" || namespace == "_all" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "namespace is required for namespaced custom resources"})
+			return
+		}
+	} else {
+		namespace = ""
+	}
+
+	if !rbac.CanAccess(user, crdName, string(common.VerbScale), cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbScale), crdName, namespace, cs.Name),
+		})
+		return
+	}
+
+	var req scaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	replicas := *req.Replicas
+
+	gvk := gvr.GroupVersion().WithKind(crd.Spec.Names.Kind)
+	oldReplicas, err := scaleSubresource(ctx, cs.K8sClient, gvk, namespace, name, replicas)
+	if err != nil {
+		klog.Errorf("Failed to scale %s %s/%s: %v", crdName, namespace, name, err)
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Custom resource not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scale resource: " + err.Error()})
+		return
+	}
+
+	resourceYAML := ""
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	if err := cs.K8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cr); err == nil {
+		resourceYAML = crToYAML(cr)
+	}
+	createActionHistory(c, crdName, namespace, name, "scale", resourceYAML, map[string]interface{}{
+		"action":      "scale",
+		"oldReplicas": oldReplicas,
+		"newReplicas": replicas,
+	}, nil)
+
+	klog.Infof("User %s scaled %s %s/%s from %d to %d replicas in cluster %s",
+		user.Key(), crdName, namespace, name, oldReplicas, replicas, cs.Name)
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Resource scaled successfully",
+		"oldReplicas": oldReplicas,
+		"newReplicas": replicas,
+	})
+}
@@ -0,0 +1,26 @@
+package resources
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestScaleRejectsMissingReplicas(t *testing.T) {
+	cs := newTestClientSet(t, newTestAPIServer(t, nil))
+	r := newTestRouter(cs)
+	h := NewGenericResourceHandler[*appsv1.ReplicaSet, *appsv1.ReplicaSetList]("replicasets", false, false)
+	r.POST("/replicasets/:namespace/:name/scale", h.Scale)
+
+	for _, body := range []string{``, `{}`, `{"replica":3}`, `{"replicas":-1}`} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/replicasets/default/web/scale", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "body %q: %s", body, w.Body.String())
+	}
+}
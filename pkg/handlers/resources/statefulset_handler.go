@@ -0,0 +1,78 @@
+package resources
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+type StatefulSetHandler struct {
+	*GenericResourceHandler[*appsv1.StatefulSet, *appsv1.StatefulSetList]
+}
+
+func NewStatefulSetHandler() *StatefulSetHandler {
+	return &StatefulSetHandler{
+		GenericResourceHandler: NewGenericResourceHandler[*appsv1.StatefulSet, *appsv1.StatefulSetList](
+			"statefulsets",
+			false, // StatefulSets are namespaced resources
+			false,
+		),
+	}
+}
+
+func (h *StatefulSetHandler) Restart(c *gin.Context, namespace, name string) error {
+	statefulset := &appsv1.StatefulSet{}
+	statefulset.Name = name
+	statefulset.Namespace = namespace
+
+	return restartPodTemplate(c, statefulset)
+}
+
+// RestartStatefulSetHandler handles POST /statefulsets/:namespace/:name/restart
+// Requires 'restart' verb permission (fine-grained control)
+func (h *StatefulSetHandler) RestartStatefulSetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	user := c.MustGet("user").(model.User)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	if !rbac.CanAccess(user, "statefulsets", string(common.VerbRestart), cs.Name, namespace) {
+		klog.Warningf("User %s denied restart permission for statefulset %s/%s", user.Key(), namespace, name)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbRestart), "statefulsets", namespace, cs.Name),
+		})
+		return
+	}
+
+	if err := h.Restart(c, namespace, name); err != nil {
+		klog.Errorf("Failed to restart statefulset %s/%s: %v", namespace, name, err)
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "StatefulSet not found: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restart statefulset: " + err.Error()})
+		return
+	}
+
+	h.recordActionHistory(c, namespace, name, "restart", map[string]interface{}{
+		"action": "restart",
+		"time":   time.Now().Format(time.RFC3339),
+	})
+
+	klog.Infof("User %s restarted statefulset %s/%s in cluster %s", user.Key(), namespace, name, cs.Name)
+	c.JSON(http.StatusOK, gin.H{"message": "StatefulSet restarted successfully"})
+}
+
+func (h *StatefulSetHandler) registerCustomRoutes(group *gin.RouterGroup) {
+	group.POST("/:namespace/:name/restart", h.RestartStatefulSetHandler)
+	group.POST("/:namespace/:name/scale", h.Scale)
+}
@@ -0,0 +1,301 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metatable "k8s.io/apimachinery/pkg/api/meta/table"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// tableAcceptHeader asks the API server for server-side printed tables, the output kubectl get uses
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// wantsTable reports whether a list request asked for table output with ?as=table
+func wantsTable(c *gin.Context) bool {
+	return c.Query("as") == "table"
+}
+
+// fetchTable lists a resource as a meta.k8s.io/v1 Table, rows carry the object metadata.
+// The query parameters of the request (selectors, limit, continue) are passed through.
+func fetchTable(c *gin.Context, gvr schema.GroupVersionResource, namespace string) (*metav1.Table, error) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	segments := []string{"/apis", gvr.Group, gvr.Version}
+	if gvr.Group == "" {
+		segments = []string{"/api", gvr.Version}
+	}
+	if namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+	segments = append(segments, gvr.Resource)
+
+	req := cs.K8sClient.ClientSet.Discovery().RESTClient().Get().
+		AbsPath(segments...).
+		SetHeader("Accept", tableAcceptHeader).
+		Param("includeObject", string(metav1.IncludeMetadata))
+	for _, param := range []string{"labelSelector", "fieldSelector", "limit", "continue"} {
+		if v := c.Query(param); v != "" {
+			req = req.Param(param, v)
+		}
+	}
+
+	raw, err := req.Do(c.Request.Context()).Raw()
+	if err != nil {
+		return nil, err
+	}
+	table := &metav1.Table{}
+	if err := json.Unmarshal(raw, table); err != nil {
+		return nil, fmt.Errorf("failed to decode table: %w", err)
+	}
+	if table.Kind != "Table" {
+		return nil, fmt.Errorf("%s does not support table output", gvr.String())
+	}
+	return table, nil
+}
+
+// finishTable drops the rows the user may not see, trims their metadata, sorts them newest first
+// like list does and removes the wide columns unless ?wide=true was requested
+func finishTable(c *gin.Context, table *metav1.Table, visible func(obj metav1.Object) bool) error {
+	type row struct {
+		metav1.TableRow
+		meta *metav1.PartialObjectMetadata
+	}
+	rows := make([]row, 0, len(table.Rows))
+	for _, r := range table.Rows {
+		obj := &metav1.PartialObjectMetadata{}
+		if len(r.Object.Raw) > 0 {
+			if err := json.Unmarshal(r.Object.Raw, obj); err != nil {
+				return fmt.Errorf("failed to decode table row: %w", err)
+			}
+		}
+		if !visible(obj) {
+			continue
+		}
+		trimObjectMeta(obj)
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		r.Object = runtime.RawExtension{Raw: raw}
+		rows = append(rows, row{TableRow: r, meta: obj})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		t1, t2 := rows[i].meta.CreationTimestamp, rows[j].meta.CreationTimestamp
+		if t1.Equal(&t2) {
+			return rows[i].meta.Name < rows[j].meta.Name
+		}
+		return t1.After(t2.Time)
+	})
+
+	table.Rows = make([]metav1.TableRow, len(rows))
+	for i := range rows {
+		table.Rows[i] = rows[i].TableRow
+	}
+
+	if c.Query("wide") != "true" {
+		narrowTable(table)
+	}
+	return nil
+}
+
+// narrowTable keeps only the priority 0 columns, which kubectl get shows without -o wide
+func narrowTable(table *metav1.Table) {
+	keep := make([]int, 0, len(table.ColumnDefinitions))
+	columns := make([]metav1.TableColumnDefinition, 0, len(table.ColumnDefinitions))
+	for i, col := range table.ColumnDefinitions {
+		if col.Priority == 0 {
+			keep = append(keep, i)
+			columns = append(columns, col)
+		}
+	}
+	if len(columns) == len(table.ColumnDefinitions) {
+		return
+	}
+	table.ColumnDefinitions = columns
+	for i := range table.Rows {
+		cells := make([]interface{}, 0, len(keep))
+		for _, idx := range keep {
+			if idx < len(table.Rows[i].Cells) {
+				cells = append(cells, table.Rows[i].Cells[idx])
+			}
+		}
+		table.Rows[i].Cells = cells
+	}
+}
+
+// defaultPrinterColumns is what the API server shows for CRD versions without additionalPrinterColumns
+var defaultPrinterColumns = []apiextensionsv1.CustomResourceColumnDefinition{
+	{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+}
+
+// printerColumnsTable renders custom resources with the additionalPrinterColumns of a CRD version,
+// mirroring the API server's table convertor. It is used when the server cannot print tables.
+func printerColumnsTable(crd *apiextensionsv1.CustomResourceDefinition, version string, list *unstructured.UnstructuredList) (*metav1.Table, error) {
+	columns := defaultPrinterColumns
+	for _, v := range crd.Spec.Versions {
+		if v.Name == version && len(v.AdditionalPrinterColumns) > 0 {
+			columns = v.AdditionalPrinterColumns
+			break
+		}
+	}
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name", Description: "Name of the resource"},
+		},
+	}
+	table.Kind = "Table"
+	table.APIVersion = metav1.SchemeGroupVersion.String()
+	table.ResourceVersion = list.GetResourceVersion()
+	table.Continue = list.GetContinue()
+
+	paths := make([]*jsonpath.JSONPath, 0, len(columns))
+	for _, col := range columns {
+		path := jsonpath.New(col.Name)
+		if err := path.Parse(fmt.Sprintf("{%s}", col.JSONPath)); err != nil {
+			return nil, fmt.Errorf("unrecognized column definition %q", col.JSONPath)
+		}
+		path.AllowMissingKeys(true)
+		paths = append(paths, path)
+		table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{
+			Name:        col.Name,
+			Type:        col.Type,
+			Format:      col.Format,
+			Description: col.Description,
+			Priority:    col.Priority,
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	for i := range list.Items {
+		item := &list.Items[i]
+		cells := make([]interface{}, 1, 1+len(paths))
+		cells[0] = item.GetName()
+		for j, path := range paths {
+			results, err := path.FindResults(item.Object)
+			if err != nil || len(results) == 0 || len(results[0]) == 0 {
+				cells = append(cells, nil)
+				continue
+			}
+			value := results[0][0].Interface()
+			if columns[j].Type == "string" {
+				if err := path.PrintResults(buf, []reflect.Value{reflect.ValueOf(value)}); err == nil {
+					cells = append(cells, buf.String())
+				} else {
+					cells = append(cells, nil)
+				}
+				buf.Reset()
+				continue
+			}
+			cells = append(cells, printerCell(columns[j].Type, value))
+		}
+
+		meta := &metav1.PartialObjectMetadata{}
+		meta.SetGroupVersionKind(item.GroupVersionKind())
+		meta.ObjectMeta = metav1.ObjectMeta{
+			Name:              item.GetName(),
+			Namespace:         item.GetNamespace(),
+			UID:               item.GetUID(),
+			ResourceVersion:   item.GetResourceVersion(),
+			CreationTimestamp: item.GetCreationTimestamp(),
+			DeletionTimestamp: item.GetDeletionTimestamp(),
+			Labels:            item.GetLabels(),
+			Annotations:       item.GetAnnotations(),
+			OwnerReferences:   item.GetOwnerReferences(),
+		}
+		raw, err := json.Marshal(meta)
+		if err != nil {
+			return nil, err
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells:  cells,
+			Object: runtime.RawExtension{Raw: raw},
+		})
+	}
+	return table, nil
+}
+
+// printerCell converts a JSONPath result to the cell value of a printer column type
+func printerCell(columnType string, value interface{}) interface{} {
+	switch columnType {
+	case "integer":
+		switch v := value.(type) {
+		case int64:
+			return v
+		case float64:
+			return int64(v)
+		}
+	case "number":
+		switch v := value.(type) {
+		case int64:
+			return float64(v)
+		case float64:
+			return v
+		}
+	case "boolean":
+		if v, ok := value.(bool); ok {
+			return v
+		}
+	case "date":
+		if v, ok := value.(string); ok {
+			var timestamp metav1.Time
+			if err := timestamp.UnmarshalQueryParameter(v); err != nil {
+				return "<invalid>"
+			}
+			return metatable.ConvertToHumanReadableDateType(timestamp)
+		}
+	}
+	return nil
+}
+
+// listTable handles GET /:resource/:namespace?as=table for built-in resources,
+// the columns are the ones kubectl get prints, ?wide=true adds the -o wide columns
+func (h *GenericResourceHandler[T, V]) listTable(c *gin.Context) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+
+	gvk, err := apiutil.GVKForObject(reflect.New(h.objectType).Interface().(T), cs.K8sClient.Scheme())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	mapping, err := cs.K8sClient.Mapper.MappingFor(gvk)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	namespace := c.Param("namespace")
+	listNamespace := ""
+	if !h.isClusterScoped && namespace != "" && namespace != "_all" {
+		listNamespace = namespace
+	}
+
+	table, err := fetchTable(c, mapping.Resource, listNamespace)
+	if err != nil {
+		klog.Errorf("Failed to list %s as table: %v", h.name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := finishTable(c, table, func(obj metav1.Object) bool {
+		return h.visible(c, namespace, obj)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, table)
+}
@@ -0,0 +1,78 @@
+package resources
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// testTable is a server-side printed table with one priority 1 (-o wide) column
+func testTable(wideColumn string, name, namespace string) *metav1.Table {
+	meta, _ := json.Marshal(metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	})
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Status", Type: "string"},
+			{Name: wideColumn, Type: "string", Priority: 1},
+		},
+		Rows: []metav1.TableRow{{
+			Cells:  []interface{}{name, "Ready", "10.0.0.1"},
+			Object: runtime.RawExtension{Raw: meta},
+		}},
+	}
+	table.Kind = "Table"
+	table.APIVersion = "meta.k8s.io/v1"
+	return table
+}
+
+func columnNames(table *metav1.Table) []string {
+	names := make([]string, 0, len(table.ColumnDefinitions))
+	for _, col := range table.ColumnDefinitions {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+func TestListAsTable(t *testing.T) {
+	server := newTestAPIServer(t, map[string]interface{}{
+		"/api/v1/pods":  testTable("IP", "web-1", "default"),
+		"/api/v1/nodes": testTable("Internal-IP", "node-1", ""),
+	})
+	cs := newTestClientSet(t, server)
+	r := newTestRouter(cs)
+	r.GET("/pods/:namespace", NewPodHandler().List)
+	r.GET("/nodes", NewNodeHandler().List)
+
+	tests := []struct {
+		name        string
+		url         string
+		wantColumns []string
+	}{
+		{"pods wide", "/pods/_all?as=table&wide=true", []string{"Name", "Status", "IP"}},
+		{"pods narrow", "/pods/_all?as=table", []string{"Name", "Status"}},
+		{"nodes wide", "/nodes?as=table&wide=true", []string{"Name", "Status", "Internal-IP"}},
+		{"nodes narrow", "/nodes?as=table", []string{"Name", "Status"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var table metav1.Table
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &table))
+			assert.Equal(t, "Table", table.Kind)
+			assert.Equal(t, tt.wantColumns, columnNames(&table))
+			require.Len(t, table.Rows, 1)
+			assert.Len(t, table.Rows[0].Cells, len(tt.wantColumns))
+		})
+	}
+}
@@ -0,0 +1,599 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// topologyBuilder collects the nodes and edges of a namespace graph.
+// Only resource types the user may get are listed or referenced.
+type topologyBuilder struct {
+	ctx       context.Context
+	cs        *cluster.ClientSet
+	user      model.User
+	namespace string
+
+	nodes     map[string]*common.TopologyNode
+	order     []string
+	listed    map[string]bool
+	edges     map[string]common.TopologyEdge
+	edgeOrder []string
+}
+
+func topologyID(resourceType, namespace, name string) string {
+	return resourceType + "/" + namespace + "/" + name
+}
+
+// allowed reports whether the user may read resourceType, cluster-scoped types are checked like their _all routes
+func (b *topologyBuilder) allowed(resourceType string, clusterScoped bool) bool {
+	namespace := b.namespace
+	if clusterScoped {
+		namespace = "_all"
+	}
+	return rbac.CanAccess(b.user, resourceType, string(common.VerbGet), b.cs.Name, namespace)
+}
+
+// list fills list with the objects of resourceType in the namespace, it returns false when the
+// type is not allowed or not served by the cluster (e.g. Gateway API CRDs are not installed)
+func (b *topologyBuilder) list(resourceType string, list client.ObjectList) bool {
+	if !b.allowed(resourceType, false) {
+		return false
+	}
+	if err := b.cs.K8sClient.List(b.ctx, list, client.InNamespace(b.namespace)); err != nil {
+		if !meta.IsNoMatchError(err) {
+			klog.Warningf("Failed to list %s in %s for topology: %v", resourceType, b.namespace, err)
+		}
+		return false
+	}
+	b.listed[resourceType] = true
+	return true
+}
+
+func (b *topologyBuilder) addNode(resourceType, apiVersion, namespace, name, health, status string) string {
+	id := topologyID(resourceType, namespace, name)
+	if _, exists := b.nodes[id]; !exists {
+		b.order = append(b.order, id)
+	}
+	b.nodes[id] = &common.TopologyNode{
+		ID:         id,
+		Type:       resourceType,
+		APIVersion: apiVersion,
+		Name:       name,
+		Namespace:  namespace,
+		Health:     health,
+		Status:     status,
+	}
+	return id
+}
+
+// ref returns the node of a referenced object, adding a placeholder when it was not collected:
+// missing if its type was listed, unknown otherwise. It returns "" if the user may not see the type.
+func (b *topologyBuilder) ref(r common.RelatedResource, clusterScoped bool) string {
+	if r.Name == "" {
+		return ""
+	}
+	id := topologyID(r.Type, r.Namespace, r.Name)
+	if _, exists := b.nodes[id]; exists {
+		return id
+	}
+	if !b.allowed(r.Type, clusterScoped) {
+		return ""
+	}
+	health := common.HealthUnknown
+	if b.listed[r.Type] {
+		health = common.HealthMissing
+	}
+	return b.addNode(r.Type, r.APIVersion, r.Namespace, r.Name, health, "")
+}
+
+func (b *topologyBuilder) addEdge(source, target, edgeType string) {
+	if source == "" || target == "" || source == target {
+		return
+	}
+	key := source + "|" + target + "|" + edgeType
+	if _, exists := b.edges[key]; exists {
+		return
+	}
+	b.edges[key] = common.TopologyEdge{Source: source, Target: target, Type: edgeType}
+	b.edgeOrder = append(b.edgeOrder, key)
+}
+
+// addOwners links an object to its owners, owner kinds outside the collected types are resolved through discovery
+func (b *topologyBuilder) addOwners(id string, obj metav1.Object) {
+	for _, owner := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err != nil {
+			continue
+		}
+		mapping, err := b.cs.K8sClient.Mapper.MappingFor(gv.WithKind(owner.Kind))
+		if err != nil {
+			continue
+		}
+		ownerID := b.ref(common.RelatedResource{
+			Type:       kube.ResourceName(mapping.Resource),
+			APIVersion: owner.APIVersion,
+			Name:       owner.Name,
+			Namespace:  obj.GetNamespace(),
+		}, mapping.Scope.Name() != meta.RESTScopeNameNamespace)
+		b.addEdge(ownerID, id, "owns")
+	}
+}
+
+// addMounts links a pod template to the ConfigMaps, Secrets and PVCs it uses
+func (b *topologyBuilder) addMounts(id string, template *corev1.PodTemplateSpec) {
+	for _, r := range discoverConfigs(b.namespace, template) {
+		b.addEdge(id, b.ref(r, false), "mounts")
+	}
+}
+
+func (b *topologyBuilder) topology() common.Topology {
+	t := common.Topology{
+		Namespace: b.namespace,
+		Nodes:     make([]common.TopologyNode, 0, len(b.order)),
+		Edges:     make([]common.TopologyEdge, 0, len(b.edgeOrder)),
+	}
+	for _, id := range b.order {
+		t.Nodes = append(t.Nodes, *b.nodes[id])
+	}
+	for _, key := range b.edgeOrder {
+		t.Edges = append(t.Edges, b.edges[key])
+	}
+	return t
+}
+
+func podHealth(pod *corev1.Pod) (string, string) {
+	if pod.DeletionTimestamp != nil {
+		return common.HealthProgressing, "Terminating"
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, s := range statuses {
+		if w := s.State.Waiting; w != nil && w.Reason != "" && w.Reason != "ContainerCreating" && w.Reason != "PodInitializing" {
+			return common.HealthDegraded, w.Reason
+		}
+	}
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return common.HealthHealthy, "Completed"
+	case corev1.PodFailed:
+		if pod.Status.Reason != "" {
+			return common.HealthDegraded, pod.Status.Reason
+		}
+		return common.HealthDegraded, "Failed"
+	case corev1.PodPending:
+		return common.HealthProgressing, "Pending"
+	case corev1.PodRunning:
+		ready := 0
+		for _, s := range pod.Status.ContainerStatuses {
+			if s.Ready {
+				ready++
+			}
+		}
+		status := fmt.Sprintf("Running %d/%d", ready, len(pod.Spec.Containers))
+		if ready == len(pod.Spec.Containers) {
+			return common.HealthHealthy, status
+		}
+		return common.HealthProgressing, status
+	}
+	return common.HealthUnknown, string(pod.Status.Phase)
+}
+
+func deploymentHealth(deployment *appsv1.Deployment) (string, string) {
+	status := getRolloutStatus(deployment)
+	summary := fmt.Sprintf("%d/%d ready", status.ReadyReplicas, status.Replicas)
+	switch {
+	case status.Failed:
+		return common.HealthDegraded, summary
+	case status.Done:
+		return common.HealthHealthy, summary
+	default:
+		return common.HealthProgressing, summary
+	}
+}
+
+func replicasHealth(desired, ready, updated int32) (string, string) {
+	summary := fmt.Sprintf("%d/%d ready", ready, desired)
+	if ready >= desired && updated >= desired {
+		return common.HealthHealthy, summary
+	}
+	return common.HealthProgressing, summary
+}
+
+func jobHealth(job *batchv1.Job) (string, string) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return common.HealthHealthy, "Complete"
+		case batchv1.JobFailed:
+			return common.HealthDegraded, "Failed"
+		case batchv1.JobSuspended:
+			return common.HealthHealthy, "Suspended"
+		}
+	}
+	return common.HealthProgressing, fmt.Sprintf("%d active", job.Status.Active)
+}
+
+func hpaHealth(hpa *autoscalingv2.HorizontalPodAutoscaler) (string, string) {
+	summary := fmt.Sprintf("%d/%d replicas", hpa.Status.CurrentReplicas, hpa.Status.DesiredReplicas)
+	for _, cond := range hpa.Status.Conditions {
+		if (cond.Type == autoscalingv2.AbleToScale || cond.Type == autoscalingv2.ScalingActive) && cond.Status == corev1.ConditionFalse {
+			return common.HealthDegraded, cond.Reason
+		}
+	}
+	return common.HealthHealthy, summary
+}
+
+func httpRouteHealth(route *gatewayapiv1.HTTPRoute) (string, string) {
+	if len(route.Status.Parents) == 0 {
+		return common.HealthProgressing, "Pending"
+	}
+	for _, parent := range route.Status.Parents {
+		for _, cond := range parent.Conditions {
+			if cond.Type == string(gatewayapiv1.RouteConditionAccepted) && cond.Status != metav1.ConditionTrue {
+				return common.HealthDegraded, cond.Reason
+			}
+		}
+	}
+	return common.HealthHealthy, "Accepted"
+}
+
+// GetNamespaceTopology handles GET /topology/:namespace.
+// It returns the objects of a namespace as a graph: ownership, service selectors, ingress and
+// HTTPRoute backends, ConfigMap/Secret/PVC mounts, PVC→PV→StorageClass bindings and HPA targets.
+func GetNamespaceTopology(c *gin.Context) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
+	namespace := c.Param("namespace")
+
+	if !rbac.CanAccessNamespace(user, cs.Name, namespace) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": rbac.NoAccess(user.Key(), string(common.VerbGet), "namespaces", namespace, cs.Name),
+		})
+		return
+	}
+
+	b := &topologyBuilder{
+		ctx:       c.Request.Context(),
+		cs:        cs,
+		user:      user,
+		namespace: namespace,
+		nodes:     map[string]*common.TopologyNode{},
+		listed:    map[string]bool{},
+		edges:     map[string]common.TopologyEdge{},
+	}
+
+	// Collect the objects of the namespace first so references can tell missing objects apart
+	var deployments appsv1.DeploymentList
+	if b.list("deployments", &deployments) {
+		for i := range deployments.Items {
+			d := &deployments.Items[i]
+			health, status := deploymentHealth(d)
+			b.addNode("deployments", "apps/v1", d.Namespace, d.Name, health, status)
+		}
+	}
+	var replicaSets appsv1.ReplicaSetList
+	if b.list("replicasets", &replicaSets) {
+		for i := range replicaSets.Items {
+			rs := &replicaSets.Items[i]
+			desired := int32(1)
+			if rs.Spec.Replicas != nil {
+				desired = *rs.Spec.Replicas
+			}
+			// Old revisions scaled to zero only add noise to the graph
+			if desired == 0 && rs.Status.Replicas == 0 {
+				continue
+			}
+			health, status := replicasHealth(desired, rs.Status.ReadyReplicas, desired)
+			b.addNode("replicasets", "apps/v1", rs.Namespace, rs.Name, health, status)
+		}
+	}
+	var statefulSets appsv1.StatefulSetList
+	if b.list("statefulsets", &statefulSets) {
+		for i := range statefulSets.Items {
+			sts := &statefulSets.Items[i]
+			desired := int32(1)
+			if sts.Spec.Replicas != nil {
+				desired = *sts.Spec.Replicas
+			}
+			health, status := replicasHealth(desired, sts.Status.ReadyReplicas, sts.Status.UpdatedReplicas)
+			b.addNode("statefulsets", "apps/v1", sts.Namespace, sts.Name, health, status)
+		}
+	}
+	var daemonSets appsv1.DaemonSetList
+	if b.list("daemonsets", &daemonSets) {
+		for i := range daemonSets.Items {
+			ds := &daemonSets.Items[i]
+			health, status := replicasHealth(ds.Status.DesiredNumberScheduled, ds.Status.NumberReady, ds.Status.UpdatedNumberScheduled)
+			b.addNode("daemonsets", "apps/v1", ds.Namespace, ds.Name, health, status)
+		}
+	}
+	var cronJobs batchv1.CronJobList
+	if b.list("cronjobs", &cronJobs) {
+		for i := range cronJobs.Items {
+			cj := &cronJobs.Items[i]
+			status := cj.Spec.Schedule
+			if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
+				status = "Suspended"
+			}
+			b.addNode("cronjobs", "batch/v1", cj.Namespace, cj.Name, common.HealthHealthy, status)
+		}
+	}
+	var jobs batchv1.JobList
+	if b.list("jobs", &jobs) {
+		for i := range jobs.Items {
+			job := &jobs.Items[i]
+			health, status := jobHealth(job)
+			b.addNode("jobs", "batch/v1", job.Namespace, job.Name, health, status)
+		}
+	}
+	var pods corev1.PodList
+	if b.list("pods", &pods) {
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			health, status := podHealth(pod)
+			b.addNode("pods", "v1", pod.Namespace, pod.Name, health, status)
+		}
+	}
+	var services corev1.ServiceList
+	if b.list("services", &services) {
+		for i := range services.Items {
+			svc := &services.Items[i]
+			b.addNode("services", "v1", svc.Namespace, svc.Name, common.HealthHealthy, string(svc.Spec.Type))
+		}
+	}
+	var ingresses networkingv1.IngressList
+	if b.list("ingresses", &ingresses) {
+		for i := range ingresses.Items {
+			ing := &ingresses.Items[i]
+			health, status := common.HealthProgressing, "No address"
+			if len(ing.Status.LoadBalancer.Ingress) > 0 {
+				health, status = common.HealthHealthy, ""
+			}
+			b.addNode("ingresses", "networking.k8s.io/v1", ing.Namespace, ing.Name, health, status)
+		}
+	}
+	var httpRoutes gatewayapiv1.HTTPRouteList
+	if b.list("httproutes", &httpRoutes) {
+		for i := range httpRoutes.Items {
+			route := &httpRoutes.Items[i]
+			health, status := httpRouteHealth(route)
+			b.addNode("httproutes", gatewayapiv1.GroupVersion.String(), route.Namespace, route.Name, health, status)
+		}
+	}
+	var configMaps corev1.ConfigMapList
+	if b.list("configmaps", &configMaps) {
+		for _, cm := range configMaps.Items {
+			b.addNode("configmaps", "v1", cm.Namespace, cm.Name, common.HealthHealthy, "")
+		}
+	}
+	var secrets corev1.SecretList
+	if b.list("secrets", &secrets) {
+		for _, secret := range secrets.Items {
+			// Helm keeps one release secret per revision, workloads never reference them
+			if secret.Type == "helm.sh/release.v1" {
+				continue
+			}
+			b.addNode("secrets", "v1", secret.Namespace, secret.Name, common.HealthHealthy, string(secret.Type))
+		}
+	}
+	var pvcs corev1.PersistentVolumeClaimList
+	if b.list("persistentvolumeclaims", &pvcs) {
+		for i := range pvcs.Items {
+			pvc := &pvcs.Items[i]
+			health := common.HealthHealthy
+			switch pvc.Status.Phase {
+			case corev1.ClaimPending:
+				health = common.HealthProgressing
+			case corev1.ClaimLost:
+				health = common.HealthDegraded
+			}
+			b.addNode("persistentvolumeclaims", "v1", pvc.Namespace, pvc.Name, health, string(pvc.Status.Phase))
+		}
+	}
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if b.list("horizontalpodautoscalers", &hpas) {
+		for i := range hpas.Items {
+			hpa := &hpas.Items[i]
+			health, status := hpaHealth(hpa)
+			b.addNode("horizontalpodautoscalers", "autoscaling/v2", hpa.Namespace, hpa.Name, health, status)
+		}
+	}
+
+	// Ownership, and mounts for pod templates that are not managed by another object
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		id := topologyID("deployments", d.Namespace, d.Name)
+		b.addOwners(id, d)
+		b.addMounts(id, &d.Spec.Template)
+	}
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		id := topologyID("replicasets", rs.Namespace, rs.Name)
+		if _, exists := b.nodes[id]; !exists {
+			continue
+		}
+		b.addOwners(id, rs)
+		if metav1.GetControllerOf(rs) == nil {
+			b.addMounts(id, &rs.Spec.Template)
+		}
+	}
+	for i := range statefulSets.Items {
+		sts := &statefulSets.Items[i]
+		id := topologyID("statefulsets", sts.Namespace, sts.Name)
+		b.addOwners(id, sts)
+		b.addMounts(id, &sts.Spec.Template)
+	}
+	for i := range daemonSets.Items {
+		ds := &daemonSets.Items[i]
+		id := topologyID("daemonsets", ds.Namespace, ds.Name)
+		b.addOwners(id, ds)
+		b.addMounts(id, &ds.Spec.Template)
+	}
+	for i := range cronJobs.Items {
+		cj := &cronJobs.Items[i]
+		id := topologyID("cronjobs", cj.Namespace, cj.Name)
+		b.addOwners(id, cj)
+		b.addMounts(id, &cj.Spec.JobTemplate.Spec.Template)
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		id := topologyID("jobs", job.Namespace, job.Name)
+		b.addOwners(id, job)
+		if metav1.GetControllerOf(job) == nil {
+			b.addMounts(id, &job.Spec.Template)
+		}
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		id := topologyID("pods", pod.Namespace, pod.Name)
+		b.addOwners(id, pod)
+		if metav1.GetControllerOf(pod) == nil {
+			b.addMounts(id, &corev1.PodTemplateSpec{Spec: pod.Spec})
+		}
+
+		// Services whose selector matches the pod labels
+		if len(pod.Labels) > 0 && b.listed["services"] {
+			selected, err := discoverServices(b.ctx, cs.K8sClient, namespace, &metav1.LabelSelector{MatchLabels: pod.Labels})
+			if err != nil {
+				klog.Warningf("Failed to discover services for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				continue
+			}
+			for _, svc := range selected {
+				b.addEdge(b.ref(svc, false), id, "selects")
+			}
+		}
+	}
+
+	// Services without any selected pod that is ready cannot serve traffic
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if len(svc.Spec.Selector) == 0 || !b.listed["pods"] {
+			continue
+		}
+		svcID := topologyID("services", svc.Namespace, svc.Name)
+		ready := false
+		for _, edge := range b.edges {
+			if edge.Source == svcID && edge.Type == "selects" && b.nodes[edge.Target].Health == common.HealthHealthy {
+				ready = true
+				break
+			}
+		}
+		if !ready {
+			b.nodes[svcID].Health = common.HealthDegraded
+			b.nodes[svcID].Status = "No ready endpoints"
+		}
+	}
+
+	for i := range ingresses.Items {
+		ing := &ingresses.Items[i]
+		id := topologyID("ingresses", ing.Namespace, ing.Name)
+		for _, svc := range discoverIngressServices(ing.Namespace, ing) {
+			b.addEdge(id, b.ref(svc, false), "routes")
+		}
+	}
+	for i := range httpRoutes.Items {
+		route := &httpRoutes.Items[i]
+		id := topologyID("httproutes", route.Namespace, route.Name)
+		for _, r := range getHTTPRouteRelatedResouces(route, route.Namespace) {
+			if r.Type == "services" {
+				b.addEdge(id, b.ref(r, false), "routes")
+			} else {
+				b.addEdge(b.ref(r, false), id, "parent")
+			}
+		}
+	}
+
+	// PVC → PV → StorageClass
+	storageClasses := map[string]string{}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		id := topologyID("persistentvolumeclaims", pvc.Namespace, pvc.Name)
+		className := ""
+		if pvc.Spec.StorageClassName != nil {
+			className = *pvc.Spec.StorageClassName
+		}
+		if pvc.Spec.VolumeName != "" && b.allowed("persistentvolumes", true) {
+			pvID := topologyID("persistentvolumes", "", pvc.Spec.VolumeName)
+			if _, exists := b.nodes[pvID]; !exists {
+				var pv corev1.PersistentVolume
+				err := cs.K8sClient.Get(b.ctx, client.ObjectKey{Name: pvc.Spec.VolumeName}, &pv)
+				switch {
+				case err == nil:
+					health := common.HealthHealthy
+					if pv.Status.Phase == corev1.VolumeFailed {
+						health = common.HealthDegraded
+					}
+					b.addNode("persistentvolumes", "v1", "", pv.Name, health, string(pv.Status.Phase))
+					if pv.Spec.StorageClassName != "" {
+						storageClasses[pvID] = pv.Spec.StorageClassName
+					}
+				case errors.IsNotFound(err):
+					b.addNode("persistentvolumes", "v1", "", pvc.Spec.VolumeName, common.HealthMissing, "")
+				default:
+					// Keep the claim bound in the graph even if its volume could not be read
+					b.addNode("persistentvolumes", "v1", "", pvc.Spec.VolumeName, common.HealthUnknown, "")
+				}
+			}
+			b.addEdge(id, pvID, "binds")
+		} else if className != "" {
+			// Unbound claims wait for their StorageClass to provision a volume
+			storageClasses[id] = className
+		}
+	}
+	if len(storageClasses) > 0 && b.allowed("storageclasses", true) {
+		sources := make([]string, 0, len(storageClasses))
+		for source := range storageClasses {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		for _, source := range sources {
+			className := storageClasses[source]
+			scID := topologyID("storageclasses", "", className)
+			if _, exists := b.nodes[scID]; !exists {
+				var sc storagev1.StorageClass
+				health := common.HealthHealthy
+				if err := cs.K8sClient.Get(b.ctx, client.ObjectKey{Name: className}, &sc); errors.IsNotFound(err) {
+					health = common.HealthMissing
+				} else if err != nil {
+					health = common.HealthUnknown
+				}
+				b.addNode("storageclasses", "storage.k8s.io/v1", "", className, health, sc.Provisioner)
+			}
+			b.addEdge(source, scID, "storageclass")
+		}
+	}
+
+	for i := range hpas.Items {
+		hpa := &hpas.Items[i]
+		id := topologyID("horizontalpodautoscalers", hpa.Namespace, hpa.Name)
+		for _, target := range getAutoScalingRelatedResources(hpa, hpa.Namespace) {
+			b.addEdge(id, b.ref(target, false), "scales")
+		}
+	}
+
+	c.JSON(http.StatusOK, b.topology())
+}
@@ -0,0 +1,60 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestTopologyUnreadableVolume(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-data"},
+	}
+	cs := newTestClientSet(t, newTestAPIServer(t, nil))
+	cs.K8sClient.Client = fake.NewClientBuilder().
+		WithScheme(kube.GetScheme()).
+		WithObjects(pvc).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, ok := obj.(*corev1.PersistentVolume); ok {
+					return fmt.Errorf("etcdserver: request timed out")
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).
+		Build()
+	r := newTestRouter(cs)
+	r.GET("/topology/:namespace", GetNamespaceTopology)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/topology/default", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var topology common.Topology
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &topology))
+	nodes := map[string]common.TopologyNode{}
+	for _, node := range topology.Nodes {
+		nodes[node.ID] = node
+	}
+	pvID := topologyID("persistentvolumes", "", "pv-data")
+	require.Contains(t, nodes, pvID)
+	assert.Equal(t, common.HealthUnknown, nodes[pvID].Health)
+	for _, edge := range topology.Edges {
+		assert.Contains(t, nodes, edge.Source)
+		assert.Contains(t, nodes, edge.Target)
+	}
+}
@@ -0,0 +1,173 @@
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/cluster"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// parseSelectors converts the labelSelector and fieldSelector query parameters into list options
+func parseSelectors(c *gin.Context) ([]client.ListOption, error) {
+	var listOpts []client.ListOption
+	if labelSelector := c.Query("labelSelector"); labelSelector != "" {
+		selector, err := metav1.ParseToLabelSelector(labelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid labelSelector parameter: %w", err)
+		}
+		labelSelectorOption, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert labelSelector: %w", err)
+		}
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: labelSelectorOption})
+	}
+
+	if fieldSelector := c.Query("fieldSelector"); fieldSelector != "" {
+		fieldSelectorOption, err := fields.ParseSelector(fieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fieldSelector parameter: %w", err)
+		}
+		listOpts = append(listOpts, client.MatchingFieldsSelector{Selector: fieldSelectorOption})
+	}
+	return listOpts, nil
+}
+
+// trimObjectMeta drops managed fields and the last-applied annotation before an object is returned
+func trimObjectMeta(obj metav1.Object) {
+	obj.SetManagedFields(nil)
+	anno := obj.GetAnnotations()
+	if anno != nil {
+		delete(anno, common.KubectlAnnotation)
+		// unstructured objects return a copy of their annotations
+		obj.SetAnnotations(anno)
+	}
+}
+
+// streamWatch writes the initial snapshot and then relays watch events over SSE
+// until the client disconnects or the watch channel is closed.
+// Objects rejected by filter are dropped from the stream.
+func streamWatch(c *gin.Context, snapshot runtime.Object, w watch.Interface, filter func(obj metav1.Object) bool) {
+	if err := writeSSE(c, "snapshot", snapshot); err != nil {
+		return
+	}
+
+	// Keep-alive pings
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	flusher, _ := c.Writer.(http.Flusher)
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			_ = writeSSE(c, "close", gin.H{"message": "connection closed"})
+			return
+		case <-ticker.C:
+			_, _ = fmt.Fprintf(c.Writer, ": ping\n\n") // comment line per SSE
+			flusher.Flush()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				_ = writeSSE(c, "close", gin.H{"message": "watch channel closed"})
+				return
+			}
+
+			if event.Type == watch.Error {
+				msg := "watch error"
+				if status, ok := event.Object.(*metav1.Status); ok && status.Message != "" {
+					msg = status.Message
+				}
+				_ = writeSSE(c, "error", gin.H{"error": msg})
+				continue
+			}
+
+			obj, err := meta.Accessor(event.Object)
+			if err != nil {
+				continue
+			}
+			if filter != nil && !filter(obj) {
+				continue
+			}
+			trimObjectMeta(obj)
+
+			switch event.Type {
+			case watch.Added:
+				_ = writeSSE(c, "added", event.Object)
+			case watch.Modified:
+				_ = writeSSE(c, "modified", event.Object)
+			case watch.Deleted:
+				_ = writeSSE(c, "deleted", event.Object)
+			default:
+				// ignore bookmarks
+			}
+		}
+	}
+}
+
+// Watch implements SSE-based watch with an initial snapshot followed by added, modified and deleted events
+func (h *GenericResourceHandler[T, V]) Watch(c *gin.Context) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	ctx := c.Request.Context()
+
+	namespace := c.Param("namespace")
+	if namespace == "" {
+		namespace = "_all"
+	}
+
+	listOpts, err := parseSelectors(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.isClusterScoped && namespace != "_all" {
+		listOpts = append(listOpts, client.InNamespace(namespace))
+	}
+
+	// List from the API server directly so the watch can resume from a consistent resourceVersion
+	objectList := reflect.New(h.listType).Interface().(V)
+	if err := cs.K8sClient.WatchClient.List(ctx, objectList, listOpts...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items, err := meta.ExtractList(objectList)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to extract items from list"})
+		return
+	}
+	filter := func(obj metav1.Object) bool {
+		return h.visible(c, namespace, obj)
+	}
+	filterItems := make([]runtime.Object, 0, len(items))
+	for i := range items {
+		obj, err := meta.Accessor(items[i])
+		if err != nil {
+			continue
+		}
+		trimObjectMeta(obj)
+		if filter(obj) {
+			filterItems = append(filterItems, items[i])
+		}
+	}
+	_ = meta.SetList(objectList, filterItems)
+
+	opts := &client.ListOptions{}
+	opts.ApplyOptions(listOpts)
+	opts.Raw = &metav1.ListOptions{ResourceVersion: objectList.GetResourceVersion()}
+	watchInterface, err := cs.K8sClient.WatchClient.Watch(ctx, reflect.New(h.listType).Interface().(V), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to start watch: %v", err)})
+		return
+	}
+	defer watchInterface.Stop()
+
+	streamWatch(c, objectList, watchInterface, filter)
+}
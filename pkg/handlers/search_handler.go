@@ -1,38 +1,56 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/xhilmi/kubedash/pkg/cluster"
 	"github.com/xhilmi/kubedash/pkg/common"
 	"github.com/xhilmi/kubedash/pkg/handlers/resources"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/rbac"
 	"github.com/xhilmi/kubedash/pkg/utils"
+	"k8s.io/klog/v2"
 )
 
+// searchClusterTimeout bounds the search of one cluster, so a slow cluster does not hold up the others
+const searchClusterTimeout = 5 * time.Second
+
 type SearchHandler struct {
+	cm    *cluster.ClusterManager
 	cache *expirable.LRU[string, []common.SearchResult]
 }
 type SearchResponse struct {
 	Results []common.SearchResult `json:"results"`
 	Total   int                   `json:"total"`
+	// FailedClusters lists the clusters that failed or timed out in a multi-cluster search
+	FailedClusters []string `json:"failedClusters,omitempty"`
 }
 
-func NewSearchHandler() *SearchHandler {
+func NewSearchHandler(cm *cluster.ClusterManager) *SearchHandler {
 	return &SearchHandler{
+		cm:    cm,
 		cache: expirable.NewLRU[string, []common.SearchResult](100, nil, time.Minute*10),
 	}
 }
 
-func (h *SearchHandler) createCacheKey(query string) string {
-	return fmt.Sprintf("search:%s", query)
+// createCacheKey scopes cached results to the user and cluster, they are filtered by the roles of the user
+func (h *SearchHandler) createCacheKey(user model.User, clusterName, query string, limit int) string {
+	return fmt.Sprintf("search:%s:%s:%d:%s", user.Key(), clusterName, limit, query)
 }
 
+// Search searches the cluster of the context, keeps the results the user may get, tags them
+// with the cluster and caches them
 func (h *SearchHandler) Search(c *gin.Context, query string, limit int) ([]common.SearchResult, error) {
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	user := c.MustGet("user").(model.User)
 	var allResults []common.SearchResult
 
 	// Search in different resource types
@@ -44,9 +62,19 @@ func (h *SearchHandler) Search(c *gin.Context, query string, limit int) ([]commo
 			if err != nil {
 				continue
 			}
-			allResults = append(allResults, results...)
+			for _, result := range results {
+				if !canGetSearchResult(user, cs.Name, result) {
+					continue
+				}
+				result.Cluster = cs.Name
+				allResults = append(allResults, result)
+			}
 		}
 	}
+	if err := c.Request.Context().Err(); err != nil {
+		// Do not cache the results of a search that timed out half way
+		return nil, err
+	}
 
 	queryLower := strings.ToLower(q)
 	sortResults(allResults, queryLower)
@@ -56,11 +84,108 @@ func (h *SearchHandler) Search(c *gin.Context, query string, limit int) ([]commo
 		allResults = allResults[:limit]
 	}
 
-	h.cache.Add(h.createCacheKey(query), allResults)
+	h.cache.Add(h.createCacheKey(user, cs.Name, query, limit), allResults)
 	return allResults, nil
 }
 
-// GlobalSearch handles global search across multiple resource types
+// canGetSearchResult checks a result against the roles of the user, the same way the
+// RBAC middleware checks the detail route of the resource
+func canGetSearchResult(user model.User, clusterName string, result common.SearchResult) bool {
+	if result.ResourceType == "namespaces" {
+		return rbac.CanAccessNamespace(user, clusterName, result.Name)
+	}
+	namespace := result.Namespace
+	if namespace == "" {
+		namespace = "_all"
+	}
+	return rbac.CanAccess(user, result.ResourceType, string(common.VerbGet), clusterName, namespace)
+}
+
+// searchCluster returns the cached results of a cluster and refreshes them in the background,
+// without cached results it searches the cluster
+func (h *SearchHandler) searchCluster(c *gin.Context, cs *cluster.ClientSet, query string, limit int) ([]common.SearchResult, error) {
+	user := c.MustGet("user").(model.User)
+	// The copy outlives the request when the cache is refreshed in the background
+	cc := c.Copy()
+	cc.Set("cluster", cs)
+	if cachedResults, found := h.cache.Get(h.createCacheKey(user, cs.Name, query, limit)); found {
+		go func() {
+			// Perform search in the background to update cache
+			_, _ = h.searchWithTimeout(cc, query, limit)
+		}()
+		return cachedResults, nil
+	}
+	return h.searchWithTimeout(cc, query, limit)
+}
+
+// searchWithTimeout runs Search and gives up after searchClusterTimeout,
+// also when a search function does not honor the context
+func (h *SearchHandler) searchWithTimeout(c *gin.Context, query string, limit int) ([]common.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), searchClusterTimeout)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+
+	type searchResult struct {
+		results []common.SearchResult
+		err     error
+	}
+	done := make(chan searchResult, 1)
+	go func() {
+		results, err := h.Search(c, query, limit)
+		done <- searchResult{results, err}
+	}()
+	select {
+	case r := <-done:
+		return r.results, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("search timed out after %s", searchClusterTimeout)
+	}
+}
+
+// searchAllClusters searches every cluster the user can access in parallel. Clusters that
+// fail or time out are left out of the results and returned by name.
+func (h *SearchHandler) searchAllClusters(c *gin.Context, query string, limit int) ([]common.SearchResult, []string) {
+	user := c.MustGet("user").(model.User)
+	var clusters []*cluster.ClientSet
+	for _, cs := range h.cm.ClientSets() {
+		if rbac.CanAccessCluster(user, cs.Name) {
+			clusters = append(clusters, cs)
+		}
+	}
+
+	clusterResults := make([][]common.SearchResult, len(clusters))
+	clusterErrors := make([]error, len(clusters))
+	var wg sync.WaitGroup
+	for i, cs := range clusters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clusterResults[i], clusterErrors[i] = h.searchCluster(c, cs, query, limit)
+		}()
+	}
+	wg.Wait()
+
+	var allResults []common.SearchResult
+	var failed []string
+	for i, cs := range clusters {
+		if clusterErrors[i] != nil {
+			klog.Warningf("Search for %q in cluster %s failed: %v", query, cs.Name, clusterErrors[i])
+			failed = append(failed, cs.Name)
+			continue
+		}
+		allResults = append(allResults, clusterResults[i]...)
+	}
+
+	_, q := utils.GuessSearchResources(query)
+	sortResults(allResults, strings.ToLower(q))
+	if len(allResults) > limit {
+		allResults = allResults[:limit]
+	}
+	return allResults, failed
+}
+
+// GlobalSearch handles global search across multiple resource types. It searches the cluster
+// of the request, or with allClusters=true every cluster the user can access.
 func (h *SearchHandler) GlobalSearch(c *gin.Context) {
 	query := c.Query("q")
 	if len(query) < 2 {
@@ -71,27 +196,24 @@ func (h *SearchHandler) GlobalSearch(c *gin.Context) {
 	// Parse limit parameter
 	limitStr := c.DefaultQuery("limit", "50")
 	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit > 100 {
+	if err != nil || limit <= 0 || limit > 100 {
 		limit = 50
 	}
 
-	cacheKey := h.createCacheKey(query)
-
-	if cachedResults, found := h.cache.Get(cacheKey); found {
-		response := SearchResponse{
-			Results: cachedResults,
-			Total:   len(cachedResults),
-		}
-		go func() {
-			// Perform search in the background to update cache
-			_, _ = h.Search(c, query, limit)
-		}()
-		c.JSON(http.StatusOK, response)
+	if c.Query("allClusters") == "true" {
+		allResults, failed := h.searchAllClusters(c, query, limit)
+		c.JSON(http.StatusOK, SearchResponse{
+			Results:        allResults,
+			Total:          len(allResults),
+			FailedClusters: failed,
+		})
 		return
 	}
 
-	allResults, err := h.Search(c, query, limit)
+	cs := c.MustGet("cluster").(*cluster.ClientSet)
+	allResults, err := h.searchCluster(c, cs, query, limit)
 	if err != nil {
+		klog.Warningf("Search for %q in cluster %s failed: %v", query, cs.Name, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to perform search"})
 		return
 	}
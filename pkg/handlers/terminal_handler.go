@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xhilmi/kubedash/pkg/cluster"
@@ -10,10 +13,25 @@ import (
 	"github.com/xhilmi/kubedash/pkg/kube"
 	"github.com/xhilmi/kubedash/pkg/model"
 	"github.com/xhilmi/kubedash/pkg/rbac"
+	"github.com/xhilmi/kubedash/pkg/utils"
 	"golang.org/x/net/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
+// debugContainerTimeout bounds how long a debug container may take to pull its image and start
+const debugContainerTimeout = 2 * time.Minute
+
+// debugContainerFailures are waiting reasons a debug container does not recover from
+var debugContainerFailures = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
 type TerminalHandler struct {
 }
 
@@ -38,26 +56,143 @@ func (h *TerminalHandler) HandleTerminalWebSocket(c *gin.Context) {
 
 	user := c.MustGet("user").(model.User)
 
+	debug := c.Query("debug") == "true"
+
 	websocket.Handler(func(ws *websocket.Conn) {
 		ctx, cancel := context.WithCancel(c.Request.Context())
 		defer cancel()
-		session := kube.NewTerminalSession(cs.K8sClient, ws, namespace, podName, container)
-		defer session.Close()
 
-		if !rbac.CanAccess(user, "pods", "exec", cs.Name, namespace) {
+		verb, subResource := common.VerbExec, "exec"
+		if debug {
+			verb, subResource = common.VerbDebug, "attach"
+		}
+		if !rbac.CanAccess(user, "pods", string(verb), cs.Name, namespace) {
 			h.sendErrorMessage(
 				ws,
-				rbac.NoAccess(user.Key(), string(common.VerbExec), "pods", namespace, cs.Name),
+				rbac.NoAccess(user.Key(), string(verb), "pods", namespace, cs.Name),
 			)
+			_ = ws.Close()
 			return
 		}
 
-		if err := session.Start(ctx, "exec"); err != nil {
+		if debug {
+			var err error
+			container, err = h.createDebugContainer(ctx, cs, ws, namespace, podName, c.Query("image"), c.Query("target"))
+			if err != nil {
+				klog.Errorf("Failed to start debug container in pod %s/%s: %v", namespace, podName, err)
+				h.sendErrorMessage(ws, err.Error())
+				_ = ws.Close()
+				return
+			}
+			klog.Infof("User %s started debug container %s in pod %s/%s in cluster %s", user.Key(), container, namespace, podName, cs.Name)
+		}
+
+		session := kube.NewTerminalSession(cs.K8sClient, ws, namespace, podName, container)
+		defer session.Close()
+
+		recording, err := startTerminalRecording(cs.Name, user, &model.TerminalRecording{
+			Kind:      model.TerminalRecordingKindPod,
+			Namespace: namespace,
+			PodName:   podName,
+			Container: container,
+		})
+		if err != nil {
+			klog.Errorf("Failed to start terminal recording: %v", err)
+			h.sendErrorMessage(ws, err.Error())
+			return
+		}
+		if recording != nil {
+			defer recording.finish()
+			session.SetRecorder(recording.recorder)
+		}
+
+		if err := session.Start(ctx, subResource); err != nil {
 			klog.Errorf("Terminal session error: %v", err)
 		}
 	}).ServeHTTP(c.Writer, c.Request)
 }
 
+// createDebugContainer adds an ephemeral container to the pod through the ephemeralcontainers
+// subresource, like kubectl debug, and waits until it runs. The image defaults to the node terminal
+// image, target shares the process namespace of another container. Ephemeral containers cannot be
+// removed, the shell exits when the terminal disconnects because stdin is only attached once.
+func (h *TerminalHandler) createDebugContainer(ctx context.Context, cs *cluster.ClientSet, ws *websocket.Conn, namespace, podName, image, target string) (string, error) {
+	pods := cs.K8sClient.ClientSet.CoreV1().Pods(namespace)
+	pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod: %w", err)
+	}
+	if target != "" && !slices.ContainsFunc(pod.Spec.Containers, func(c corev1.Container) bool { return c.Name == target }) {
+		return "", fmt.Errorf("target container %s not found in pod %s", target, podName)
+	}
+	if image == "" {
+		image = common.NodeTerminalImage
+	}
+
+	name := fmt.Sprintf("%s-%s", common.DebugContainerPrefix, utils.RandomString(5))
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    image,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			Stdin:                    true,
+			StdinOnce:                true,
+			TTY:                      true,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: target,
+	})
+	if _, err := pods.UpdateEphemeralContainers(ctx, podName, pod, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to add debug container: %w", err)
+	}
+
+	h.sendMessage(ws, "info", fmt.Sprintf("waiting for debug container %s to start", name))
+	timeout := time.After(debugContainerTimeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-timeout:
+			return "", fmt.Errorf("timeout waiting for debug container %s to start", name)
+		case <-ticker.C:
+			pod, err := pods.Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			for _, status := range pod.Status.EphemeralContainerStatuses {
+				if status.Name != name {
+					continue
+				}
+				switch {
+				case status.State.Running != nil:
+					h.sendMessage(ws, "info", "")
+					return name, nil
+				case status.State.Terminated != nil:
+					return "", fmt.Errorf("debug container %s terminated: %s %s", name,
+						status.State.Terminated.Reason, status.State.Terminated.Message)
+				case status.State.Waiting != nil && debugContainerFailures[status.State.Waiting.Reason]:
+					return "", fmt.Errorf("debug container %s cannot start: %s %s", name,
+						status.State.Waiting.Reason, status.State.Waiting.Message)
+				}
+			}
+			h.sendMessage(ws, "stdout", ".")
+		}
+	}
+}
+
+// sendMessage sends a message through WebSocket
+func (h *TerminalHandler) sendMessage(conn *websocket.Conn, msgType, message string) {
+	msg := map[string]interface{}{
+		"type": msgType,
+		"data": message,
+	}
+	if err := websocket.JSON.Send(conn, msg); err != nil {
+		klog.Errorf("Failed to send message: %v", err)
+	}
+}
+
 // sendErrorMessage sends an error message through WebSocket
 func (h *TerminalHandler) sendErrorMessage(conn *websocket.Conn, message string) {
 	msg := map[string]interface{}{
@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+	"github.com/xhilmi/kubedash/pkg/utils"
+	"gorm.io/gorm"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// terminalRecordingCleanupInterval is how often recordings past the retention are removed
+	terminalRecordingCleanupInterval = time.Hour
+
+	// defaultReplayPageEvents and maxReplayPageEvents bound the events of one replay page
+	defaultReplayPageEvents = 1000
+	maxReplayPageEvents     = 10000
+	// maxReplayPageBytes ends a replay page early when its events carry a lot of output
+	maxReplayPageBytes = 4 * 1024 * 1024
+)
+
+// terminalRecording is a running recording of a terminal session
+type terminalRecording struct {
+	record   *model.TerminalRecording
+	file     *os.File
+	recorder *kube.CastRecorder
+}
+
+// startTerminalRecording starts recording a session if the cluster has recording enabled.
+// It returns nil if recording is disabled, a recording that cannot be started fails the session.
+func startTerminalRecording(clusterName string, user model.User, record *model.TerminalRecording) (*terminalRecording, error) {
+	clusterConfig, err := model.GetClusterByName(clusterName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cluster settings: %w", err)
+	}
+	if !clusterConfig.RecordTerminal {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(common.TerminalRecordingDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	record.ClusterName = clusterName
+	record.OperatorID = user.ID
+	record.OperatorName = user.Key()
+	record.StartedAt = time.Now()
+	record.FileName = fmt.Sprintf("%s-%s.cast", record.StartedAt.UTC().Format("20060102T150405"), utils.RandomString(8))
+
+	file, err := os.OpenFile(filepath.Join(common.TerminalRecordingDir, record.FileName), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording file: %w", err)
+	}
+	target := record.PodName
+	if record.Kind == model.TerminalRecordingKindNode {
+		target = record.NodeName
+	}
+	recorder, err := kube.NewCastRecorder(file, 80, 24, fmt.Sprintf("%s@%s: %s %s", user.Key(), clusterName, record.Kind, target))
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to write recording: %w", err)
+	}
+	if err := model.AddTerminalRecording(record); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to save recording: %w", err)
+	}
+
+	klog.Infof("Recording terminal session %d of user %s on %s %s in cluster %s", record.ID, user.Key(), record.Kind, target, clusterName)
+	return &terminalRecording{record: record, file: file, recorder: recorder}, nil
+}
+
+// finish closes the recording file and stores end time and size
+func (r *terminalRecording) finish() {
+	var size int64
+	if info, err := r.file.Stat(); err == nil {
+		size = info.Size()
+	}
+	if err := r.file.Close(); err != nil {
+		klog.Errorf("Failed to close terminal recording %d: %v", r.record.ID, err)
+	}
+	if err := model.FinishTerminalRecording(r.record, time.Now(), size); err != nil {
+		klog.Errorf("Failed to finish terminal recording %d: %v", r.record.ID, err)
+	}
+}
+
+// StartTerminalRecordingCleanup removes recordings older than the retention in the background
+func StartTerminalRecordingCleanup() {
+	if common.TerminalRecordingRetentionDays == 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(terminalRecordingCleanupInterval)
+		defer ticker.Stop()
+		for {
+			cleanupTerminalRecordings()
+			<-ticker.C
+		}
+	}()
+}
+
+func cleanupTerminalRecordings() {
+	cutoff := time.Now().AddDate(0, 0, -common.TerminalRecordingRetentionDays)
+	recordings, err := model.DeleteTerminalRecordingsBefore(cutoff)
+	if err != nil {
+		klog.Errorf("Failed to clean up terminal recordings: %v", err)
+		return
+	}
+	for _, r := range recordings {
+		if err := os.Remove(filepath.Join(common.TerminalRecordingDir, r.FileName)); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("Failed to remove terminal recording file %s: %v", r.FileName, err)
+		}
+	}
+	if len(recordings) > 0 {
+		klog.Infof("Removed %d terminal recordings older than %d days", len(recordings), common.TerminalRecordingRetentionDays)
+	}
+}
+
+// ListTerminalRecordings handles GET /admin/terminal-recordings/,
+// filtered by cluster, kind, user, namespace, pod and node
+func ListTerminalRecordings(c *gin.Context) {
+	page := 1
+	size := 20
+	if p := c.Query("page"); p != "" {
+		_, _ = fmt.Sscanf(p, "%d", &page)
+		if page <= 0 {
+			page = 1
+		}
+	}
+	if s := c.Query("size"); s != "" {
+		_, _ = fmt.Sscanf(s, "%d", &size)
+		if size <= 0 {
+			size = 20
+		}
+	}
+	offset := (page - 1) * size
+
+	filter := model.TerminalRecordingFilter{
+		ClusterName:  c.Query("cluster"),
+		Kind:         c.Query("kind"),
+		OperatorName: c.Query("user"),
+		Namespace:    c.Query("namespace"),
+		PodName:      c.Query("pod"),
+		NodeName:     c.Query("node"),
+	}
+	recordings, total, err := model.ListTerminalRecordings(filter, size, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list terminal recordings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recordings": recordings, "total": total, "page": page, "size": size})
+}
+
+func getTerminalRecording(c *gin.Context) (*model.TerminalRecording, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recording id"})
+		return nil, false
+	}
+	recording, err := model.GetTerminalRecordingByID(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "recording not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return recording, true
+}
+
+// DownloadTerminalRecording handles GET /admin/terminal-recordings/:id/download,
+// the cast file plays with asciinema play
+func DownloadTerminalRecording(c *gin.Context) {
+	recording, ok := getTerminalRecording(c)
+	if !ok {
+		return
+	}
+	path := filepath.Join(common.TerminalRecordingDir, recording.FileName)
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "recording file not found"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", recording.FileName))
+	c.Header("Content-Type", "application/x-asciicast")
+	c.File(path)
+}
+
+// ReplayTerminalRecording handles GET /admin/terminal-recordings/:id/replay?cursor=&limit= and returns
+// the cast header and a page of events as JSON, so a terminal in the browser can play them back.
+// Pages are read from the file at the byte cursor, the response carries the cursor of the next page
+// until the recording is read to the end.
+func ReplayTerminalRecording(c *gin.Context) {
+	recording, ok := getTerminalRecording(c)
+	if !ok {
+		return
+	}
+	limit := defaultReplayPageEvents
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
+			return
+		}
+		limit = min(n, maxReplayPageEvents)
+	}
+	var cursor int64
+	if v := c.Query("cursor"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor parameter"})
+			return
+		}
+		cursor = n
+	}
+
+	file, err := os.Open(filepath.Join(common.TerminalRecordingDir, recording.FileName))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "recording file not found"})
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	// pos is the byte offset of the next line, ScanLines reports how much of the file it consumed
+	var pos int64
+	newScanner := func() *bufio.Scanner {
+		scanner := bufio.NewScanner(file)
+		// Output events can carry a large burst of terminal output
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+			advance, token, err := bufio.ScanLines(data, atEOF)
+			pos += int64(advance)
+			return advance, token, err
+		})
+		return scanner
+	}
+
+	scanner := newScanner()
+	var header kube.CastHeader
+	if !scanner.Scan() {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "recording has no header"})
+		return
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid recording header: " + err.Error()})
+		return
+	}
+	if cursor > pos {
+		if _, err := file.Seek(cursor, io.SeekStart); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor parameter"})
+			return
+		}
+		pos = cursor
+		scanner = newScanner()
+	}
+
+	events := make([]json.RawMessage, 0, min(limit, 1024))
+	var size int
+	var next *int64
+	for {
+		start := pos
+		if !scanner.Scan() {
+			break
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if len(events) >= limit || size >= maxReplayPageBytes {
+			next = &start
+			break
+		}
+		events = append(events, json.RawMessage(append([]byte(nil), line...)))
+		size += len(line)
+	}
+	if err := scanner.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read recording: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"recording": recording,
+		"header":    header,
+		"events":    events,
+		"next":      next,
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"github.com/xhilmi/kubedash/pkg/kube"
+	"github.com/xhilmi/kubedash/pkg/model"
+)
+
+func TestReplayTerminalRecordingPages(t *testing.T) {
+	setupTestDB(t, &model.TerminalRecording{})
+	dir := t.TempDir()
+	oldDir := common.TerminalRecordingDir
+	common.TerminalRecordingDir = dir
+	t.Cleanup(func() { common.TerminalRecordingDir = oldDir })
+
+	lines := []string{`{"version":2,"width":80,"height":24,"timestamp":1700000000}`}
+	for i := range 5 {
+		lines = append(lines, fmt.Sprintf(`[%d.5,"o","line %d\r\n"]`, i, i))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "session.cast"), []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+	recording := model.TerminalRecording{ClusterName: "test", Kind: "pod", OperatorName: "admin", FileName: "session.cast"}
+	require.NoError(t, model.DB.Create(&recording).Error)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin/terminal-recordings/:id/replay", ReplayTerminalRecording)
+
+	type replayPage struct {
+		Header kube.CastHeader   `json:"header"`
+		Events []json.RawMessage `json:"events"`
+		Next   *int64            `json:"next"`
+	}
+	get := func(query string) (int, replayPage) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/admin/terminal-recordings/%d/replay?%s", recording.ID, query), nil))
+		var page replayPage
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		}
+		return w.Code, page
+	}
+
+	var events []string
+	var pageSizes []int
+	query := "limit=2"
+	for range 10 {
+		code, page := get(query)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 80, page.Header.Width)
+		pageSizes = append(pageSizes, len(page.Events))
+		for _, e := range page.Events {
+			events = append(events, string(e))
+		}
+		if page.Next == nil {
+			break
+		}
+		query = fmt.Sprintf("limit=2&cursor=%d", *page.Next)
+	}
+	assert.Equal(t, []int{2, 2, 1}, pageSizes)
+	assert.Equal(t, lines[1:], events)
+
+	code, _ := get("cursor=-1")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("limit=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
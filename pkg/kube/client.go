@@ -8,6 +8,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,6 +44,11 @@ type K8sClient struct {
 	ClientSet     *kubernetes.Clientset
 	Configuration *rest.Config
 	MetricsClient *metricsclient.Clientset
+	// WatchClient talks to the API server directly, bypassing the informer cache,
+	// so it can serve consistent list+watch streams
+	WatchClient client.WithWatch
+	// RESTMapper maps kinds to resource names from cached discovery, refreshed when CRDs change
+	Mapper *RESTMapper
 
 	cancel context.CancelFunc
 }
@@ -61,6 +67,8 @@ func NewClient(config *rest.Config) (*K8sClient, error) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	restMapper := NewRESTMapper(clientset.Discovery())
+
 	var c client.Client
 	if os.Getenv("DISABLE_CACHE") == "true" {
 		c, err = client.New(config, client.Options{
@@ -108,6 +116,26 @@ func NewClient(config *rest.Config) (*K8sClient, error) {
 			return nil, fmt.Errorf("failed to wait for cache sync")
 		}
 		c = mgr.GetClient()
+
+		// Drop the discovery cache whenever the set of CRDs changes
+		crdInformer, err := mgr.GetCache().GetInformer(ctx, &apiextensionsv1.CustomResourceDefinition{})
+		if err != nil {
+			klog.Warningf("failed to watch CRDs for discovery refresh: %v", err)
+		} else if _, err := crdInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { restMapper.Reset() },
+			UpdateFunc: func(oldObj, newObj interface{}) { resetOnCRDVersionChange(restMapper, oldObj, newObj) },
+			DeleteFunc: func(obj interface{}) { restMapper.Reset() },
+		}); err != nil {
+			klog.Warningf("failed to watch CRDs for discovery refresh: %v", err)
+		}
+	}
+
+	watchClient, err := client.NewWithWatch(config, client.Options{
+		Scheme: runtimeScheme,
+	})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create watch client: %w", err)
 	}
 
 	return &K8sClient{
@@ -115,10 +143,24 @@ func NewClient(config *rest.Config) (*K8sClient, error) {
 		ClientSet:     clientset,
 		Configuration: config,
 		MetricsClient: metricsClient,
+		WatchClient:   watchClient,
+		Mapper:        restMapper,
 		cancel:        cancel,
 	}, nil
 }
 
+// resetOnCRDVersionChange resets the mapper when the served versions or names of a CRD change,
+// status-only updates are ignored
+func resetOnCRDVersionChange(m *RESTMapper, oldObj, newObj interface{}) {
+	oldCRD, ok1 := oldObj.(*apiextensionsv1.CustomResourceDefinition)
+	newCRD, ok2 := newObj.(*apiextensionsv1.CustomResourceDefinition)
+	if ok1 && ok2 && oldCRD.Generation == newCRD.Generation &&
+		equality.Semantic.DeepEqual(oldCRD.Status.AcceptedNames, newCRD.Status.AcceptedNames) {
+		return
+	}
+	m.Reset()
+}
+
 func (k *K8sClient) Stop(name string) {
 	klog.Infof("Stopping K8s client for %s", name)
 	k.cancel()
@@ -0,0 +1,62 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxCopyErrorOutput limits how much tar error output is kept for the error message
+const maxCopyErrorOutput = 4096
+
+// limitedBuffer keeps the first bytes written to it and discards the rest
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if rest := maxCopyErrorOutput - b.Len(); rest > 0 {
+		b.Buffer.Write(p[:min(rest, len(p))])
+	}
+	return len(p), nil
+}
+
+// CopyFromPod writes a tar archive of a file or directory in a container to w, like kubectl cp.
+// The archive has a single top-level entry named after the last element of srcPath.
+// The container needs tar.
+func CopyFromPod(ctx context.Context, client *K8sClient, namespace, podName, container, srcPath string, w io.Writer) error {
+	srcPath = path.Clean(srcPath)
+	dir, base := path.Split(srcPath)
+	if dir == "" {
+		dir = "."
+	}
+	if base == "" || base == "/" {
+		// The root directory
+		base = "."
+	}
+	var stderr limitedBuffer
+	err := Exec(ctx, client, namespace, podName, container, []string{"tar", "cf", "-", "-C", dir, base}, nil, w, &stderr)
+	return copyError(err, &stderr)
+}
+
+// CopyToPod extracts the tar archive read from r into the directory destDir of a container.
+// destDir must exist, the container needs tar.
+func CopyToPod(ctx context.Context, client *K8sClient, namespace, podName, container, destDir string, r io.Reader) error {
+	var stderr limitedBuffer
+	err := Exec(ctx, client, namespace, podName, container, []string{"tar", "xmf", "-", "-C", path.Clean(destDir)}, r, nil, &stderr)
+	return copyError(err, &stderr)
+}
+
+// copyError adds the tar error output to the exec error
+func copyError(err error, stderr *limitedBuffer) error {
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package kube
+
+import (
+	"context"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// newExecutor creates an executor for the exec or attach subresource of a pod
+func newExecutor(client *K8sClient, namespace, podName, subResource string, options *corev1.PodExecOptions) (remotecommand.Executor, error) {
+	req := client.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource(subResource)
+	req.VersionedParams(options, scheme.ParameterCodec)
+
+	// TODO: use NewWebSocketExecutor
+	return remotecommand.NewSPDYExecutor(client.Configuration, "POST", req.URL())
+}
+
+// Exec runs a command in a container without a TTY and returns when it exits.
+// stdin may be nil, a non-zero exit code is returned as an error.
+func Exec(ctx context.Context, client *K8sClient, namespace, podName, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	exec, err := newExecutor(client, namespace, podName, "exec", &corev1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     stdin != nil,
+		Stdout:    stdout != nil,
+		Stderr:    stderr != nil,
+	})
+	if err != nil {
+		return err
+	}
+	return exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
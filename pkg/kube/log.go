@@ -1,30 +1,45 @@
 package kube
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"golang.org/x/net/websocket"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
 
+// AllContainers as container option streams every container of the pods, including init,
+// sidecar and ephemeral containers, with each line tagged by pod and container
+const AllContainers = "*"
+
 type PodLogStream struct {
-	Pod    corev1.Pod
-	Cancel context.CancelFunc
-	Done   chan struct{}
+	Pod       corev1.Pod
+	Container string // only set when streaming all containers
+	// ContainerID identifies the container instance, a restarted container gets a new stream
+	ContainerID string
+	Cancel      context.CancelFunc
+	Done        chan struct{}
 }
 
 type BatchLogHandler struct {
 	conn      *websocket.Conn
-	pods      map[string]*PodLogStream // key: namespace/name
+	mu        sync.Mutex
+	pods      map[string]*PodLogStream // key: namespace/name, or namespace/name/container for all containers
 	k8sClient *K8sClient
 	opts      *corev1.PodLogOptions
 	ctx       context.Context
 	cancel    context.CancelFunc
+
+	// filter is swapped by filter control messages while the streams are running
+	filter atomic.Pointer[LogFilter]
 }
 
 func NewBatchLogHandler(conn *websocket.Conn, client *K8sClient, opts *corev1.PodLogOptions) *BatchLogHandler {
@@ -40,6 +55,11 @@ func NewBatchLogHandler(conn *websocket.Conn, client *K8sClient, opts *corev1.Po
 	return l
 }
 
+// SetFilter replaces the filter applied to all streams, nil sends every line
+func (l *BatchLogHandler) SetFilter(filter *LogFilter) {
+	l.filter.Store(filter)
+}
+
 func (l *BatchLogHandler) StreamLogs(ctx context.Context) {
 	// Start heartbeat handler
 	go l.heartbeat(ctx)
@@ -55,49 +75,70 @@ func (l *BatchLogHandler) StreamLogs(ctx context.Context) {
 	l.Stop()
 }
 
-func (l *BatchLogHandler) startPodLogStream(podStream *PodLogStream) {
-	pod := podStream.Pod
-	podCtx, cancel := context.WithCancel(l.ctx)
-	podStream.Cancel = cancel
+// AllContainers reports whether every container of the pods is streamed
+func (l *BatchLogHandler) AllContainers() bool {
+	return l.opts.Container == AllContainers
+}
+
+func (l *BatchLogHandler) streamCount() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.pods)
+}
 
+func (l *BatchLogHandler) startPodLogStream(podCtx context.Context, podStream *PodLogStream) {
+	pod := podStream.Pod
 	defer func() {
 		close(podStream.Done)
 	}()
 
-	req := l.k8sClient.ClientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, l.opts)
+	opts := l.opts
+	source := pod.Name
+	if podStream.Container != "" {
+		containerOpts := *l.opts
+		containerOpts.Container = podStream.Container
+		opts = &containerOpts
+		source = pod.Name + "/" + podStream.Container
+	}
+
+	req := l.k8sClient.ClientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts)
 	podLogs, err := req.Stream(podCtx)
 	if err != nil {
-		_ = sendErrorMessage(l.conn, fmt.Sprintf("Failed to get pod logs for %s: %v", pod.Name, err))
+		_ = sendErrorMessage(l.conn, fmt.Sprintf("Failed to get pod logs for %s: %v", source, err))
 		return
 	}
 	defer func() {
 		_ = podLogs.Close()
 	}()
 
-	lw := writerFunc(func(p []byte) (int, error) {
-		logString := string(p)
-		logLines := strings.SplitSeq(logString, "\n")
-		for line := range logLines {
-			if line == "" {
-				continue
-			}
-			if len(l.pods) > 1 {
-				line = fmt.Sprintf("[%s]: %s", pod.Name, line)
+	state := &logFilterState{}
+	reader := bufio.NewReader(podLogs)
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSuffix(line, "\n")
+		if line != "" {
+			for _, out := range state.process(l.filter.Load(), line, l.opts.Timestamps) {
+				if podStream.Container != "" || l.streamCount() > 1 {
+					out = fmt.Sprintf("[%s]: %s", source, out)
+				}
+				if serr := sendMessage(l.conn, "log", out); serr != nil {
+					return
+				}
 			}
-			err := sendMessage(l.conn, "log", line)
-			if err != nil {
-				return 0, err
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
+				_ = sendErrorMessage(l.conn, fmt.Sprintf("Failed to stream pod logs for %s: %v", source, err))
 			}
+			break
 		}
-
-		return len(p), nil
-	})
-
-	_, err = io.Copy(lw, podLogs)
-	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
-		_ = sendErrorMessage(l.conn, fmt.Sprintf("Failed to stream pod logs for %s: %v", pod.Name, err))
 	}
 
+	if podStream.Container != "" {
+		_ = sendMessage(l.conn, "close", fmt.Sprintf("{\"status\":\"closed\",\"pod\":\"%s\",\"container\":\"%s\"}",
+			pod.Name, podStream.Container))
+		return
+	}
 	_ = sendMessage(l.conn, "close", fmt.Sprintf("{\"status\":\"closed\",\"pod\":\"%s\"}", pod.Name))
 }
 
@@ -120,6 +161,11 @@ func (l *BatchLogHandler) heartbeat(ctx context.Context) {
 				l.cancel() // Cancel internal context when connection is lost
 				return
 			}
+			var msg LogControlMessage
+			if err := json.Unmarshal(temp, &msg); err == nil && msg.Type == "filter" {
+				l.applyFilter(msg.Filter)
+				continue
+			}
 			if strings.Contains(string(temp), "ping") {
 				err = sendMessage(l.conn, "pong", "pong")
 				if err != nil {
@@ -132,71 +178,137 @@ func (l *BatchLogHandler) heartbeat(ctx context.Context) {
 	}
 }
 
-// AddPod adds a new pod to the batch log handler and starts streaming its logs
+// applyFilter handles a filter control message, an invalid filter keeps the current one
+func (l *BatchLogHandler) applyFilter(opts *LogFilterOptions) {
+	if opts == nil {
+		opts = &LogFilterOptions{}
+	}
+	filter, err := NewLogFilter(*opts)
+	if err != nil {
+		_ = sendErrorMessage(l.conn, err.Error())
+		return
+	}
+	l.SetFilter(filter)
+	_ = sendMessage(l.conn, "filter", "applied")
+}
+
+// AddPod adds a new pod to the batch log handler and starts streaming its logs.
+// When streaming all containers it can be called again for a pod to pick up containers that started since.
 func (l *BatchLogHandler) AddPod(pod corev1.Pod) {
+	if l.AllContainers() {
+		l.addContainers(pod)
+		return
+	}
+
 	key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
 
+	l.mu.Lock()
 	if _, exists := l.pods[key]; exists {
+		l.mu.Unlock()
 		return
 	}
-
-	podStream := &PodLogStream{
-		Pod:  pod,
-		Done: make(chan struct{}),
-	}
+	podStream := l.newStream(pod, "", "")
 	l.pods[key] = podStream
-
-	// Start streaming for this pod
-	go l.startPodLogStream(podStream)
+	l.mu.Unlock()
 
 	_ = sendMessage(l.conn, "pod_added", fmt.Sprintf("{\"pod\":\"%s\",\"namespace\":\"%s\"}",
 		pod.Name, pod.Namespace))
 }
 
+// addContainers starts a stream for every started container of the pod that has none yet.
+// Containers that have not started have no logs, they are added by a later call once they run.
+func (l *BatchLogHandler) addContainers(pod corev1.Pod) {
+	statuses := make([]corev1.ContainerStatus, 0,
+		len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses)+len(pod.Status.EphemeralContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	statuses = append(statuses, pod.Status.EphemeralContainerStatuses...)
+
+	for _, status := range statuses {
+		if status.ContainerID == "" || (status.State.Running == nil && status.State.Terminated == nil) {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, status.Name)
+
+		l.mu.Lock()
+		if existing, exists := l.pods[key]; exists {
+			if existing.ContainerID == status.ContainerID {
+				l.mu.Unlock()
+				continue
+			}
+			// The container restarted, the old stream ends on its own once it has read the last lines
+		}
+		l.pods[key] = l.newStream(pod, status.Name, status.ContainerID)
+		l.mu.Unlock()
+
+		_ = sendMessage(l.conn, "pod_added", fmt.Sprintf("{\"pod\":\"%s\",\"namespace\":\"%s\",\"container\":\"%s\"}",
+			pod.Name, pod.Namespace, status.Name))
+	}
+}
+
+// newStream creates a stream and starts it, the caller must hold l.mu
+func (l *BatchLogHandler) newStream(pod corev1.Pod, container, containerID string) *PodLogStream {
+	podCtx, cancel := context.WithCancel(l.ctx)
+	podStream := &PodLogStream{
+		Pod:         pod,
+		Container:   container,
+		ContainerID: containerID,
+		Cancel:      cancel,
+		Done:        make(chan struct{}),
+	}
+	go l.startPodLogStream(podCtx, podStream)
+	return podStream
+}
+
 // RemovePod removes a pod from the batch log handler and stops streaming its logs
 func (l *BatchLogHandler) RemovePod(pod corev1.Pod) {
-	key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
-	podStream, exists := l.pods[key]
-	if !exists {
-		return
-	}
+	prefix := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
 
-	if podStream.Cancel != nil {
+	l.mu.Lock()
+	var removed []*PodLogStream
+	for key, podStream := range l.pods {
+		if key != prefix && !strings.HasPrefix(key, prefix+"/") {
+			continue
+		}
 		podStream.Cancel()
+		removed = append(removed, podStream)
+		delete(l.pods, key)
+	}
+	l.mu.Unlock()
+	if len(removed) == 0 {
+		return
 	}
 
 	go func() {
-		<-podStream.Done
+		for _, podStream := range removed {
+			<-podStream.Done
+		}
 		_ = sendMessage(l.conn, "pod_removed", fmt.Sprintf("{\"pod\":\"%s\",\"namespace\":\"%s\"}",
 			pod.Name, pod.Namespace))
 	}()
-
-	delete(l.pods, key)
 }
 
 func (l *BatchLogHandler) Stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	for _, podStream := range l.pods {
-		if podStream.Cancel != nil {
-			podStream.Cancel()
-		}
+		podStream.Cancel()
 	}
 	l.cancel()
 	l.pods = make(map[string]*PodLogStream)
 }
 
-// writerFunc adapts a function to io.Writer so we can create
-// small writers inline inside functions and capture local state.
-type writerFunc func([]byte) (int, error)
-
-func (wf writerFunc) Write(p []byte) (int, error) {
-	return wf(p)
-}
-
 type LogsMessage struct {
-	Type string `json:"type"` // "log", "error", "connected", "close"
+	Type string `json:"type"` // "log", "error", "connected", "close", "filter"
 	Data string `json:"data"`
 }
 
+// LogControlMessage is sent by the client, {"type":"filter","filter":{...}} replaces the log filter
+type LogControlMessage struct {
+	Type   string            `json:"type"` // "ping", "filter"
+	Filter *LogFilterOptions `json:"filter,omitempty"`
+}
+
 func sendMessage(ws *websocket.Conn, msgType, data string) error {
 	msg := LogsMessage{
 		Type: msgType,
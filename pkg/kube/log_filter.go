@@ -0,0 +1,289 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLogContextLines bounds the context lines kept around a match for every stream
+const maxLogContextLines = 100
+
+// logLevels ranks the common level names, so level>=warn also matches error and fatal
+var logLevels = map[string]int{
+	"trace":       1,
+	"debug":       2,
+	"dbg":         2,
+	"info":        3,
+	"information": 3,
+	"notice":      3,
+	"warn":        4,
+	"warning":     4,
+	"error":       5,
+	"err":         5,
+	"fatal":       6,
+	"critical":    6,
+	"crit":        6,
+	"panic":       6,
+}
+
+// logFieldOperators are tried in this order, so >= is not read as >
+var logFieldOperators = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// LogFilterOptions selects the log lines sent to the client.
+// Fields are conditions on JSON logs like level>=warn or trace_id=abc, giving any of them
+// switches to JSON mode where lines that are not JSON objects never match.
+type LogFilterOptions struct {
+	Include       string   `json:"include,omitempty"`
+	Exclude       string   `json:"exclude,omitempty"`
+	CaseSensitive bool     `json:"caseSensitive,omitempty"`
+	Before        int      `json:"before,omitempty"`
+	After         int      `json:"after,omitempty"`
+	Fields        []string `json:"fields,omitempty"`
+}
+
+// logFieldCondition is one parsed field filter
+type logFieldCondition struct {
+	path  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+// LogFilter is the compiled form of LogFilterOptions, it is immutable and shared by all streams
+type LogFilter struct {
+	include       *regexp.Regexp
+	exclude       *regexp.Regexp
+	caseSensitive bool
+	before        int
+	after         int
+	conditions    []logFieldCondition
+}
+
+// NewLogFilter compiles the filter options, it returns nil if they do not filter anything
+func NewLogFilter(opts LogFilterOptions) (*LogFilter, error) {
+	f := &LogFilter{
+		caseSensitive: opts.CaseSensitive,
+		before:        min(max(opts.Before, 0), maxLogContextLines),
+		after:         min(max(opts.After, 0), maxLogContextLines),
+	}
+	var err error
+	if f.include, err = f.compile(opts.Include); err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	if f.exclude, err = f.compile(opts.Exclude); err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	for _, field := range opts.Fields {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		cond, err := f.parseCondition(field)
+		if err != nil {
+			return nil, err
+		}
+		f.conditions = append(f.conditions, cond)
+	}
+	if f.include == nil && f.exclude == nil && len(f.conditions) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func (f *LogFilter) compile(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if !f.caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// parseCondition parses <field><op><value>, the field may be a dotted path into nested objects
+func (f *LogFilter) parseCondition(expr string) (logFieldCondition, error) {
+	for i := 0; i < len(expr); i++ {
+		for _, op := range logFieldOperators {
+			if !strings.HasPrefix(expr[i:], op) {
+				continue
+			}
+			cond := logFieldCondition{
+				path:  strings.TrimSpace(expr[:i]),
+				op:    op,
+				value: strings.TrimSpace(expr[i+len(op):]),
+			}
+			if cond.path == "" {
+				return cond, fmt.Errorf("invalid field filter %q: missing field name", expr)
+			}
+			if op == "~" {
+				re, err := f.compile(cond.value)
+				if err != nil {
+					return cond, fmt.Errorf("invalid field filter %q: %w", expr, err)
+				}
+				cond.re = re
+			}
+			return cond, nil
+		}
+	}
+	return logFieldCondition{}, fmt.Errorf("invalid field filter %q: expected one of %s", expr, strings.Join(logFieldOperators, " "))
+}
+
+// Match reports whether a line passes the filter, timestamped tells that lines start with an RFC3339 timestamp
+func (f *LogFilter) Match(line string, timestamped bool) bool {
+	msg := line
+	if timestamped {
+		if ts, rest, ok := strings.Cut(line, " "); ok {
+			if _, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				msg = rest
+			}
+		}
+	}
+	if f.include != nil && !f.include.MatchString(msg) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.MatchString(msg) {
+		return false
+	}
+	if len(f.conditions) == 0 {
+		return true
+	}
+
+	msg = strings.TrimSpace(msg)
+	if !strings.HasPrefix(msg, "{") {
+		return false
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(msg), &entry); err != nil {
+		return false
+	}
+	for _, cond := range f.conditions {
+		value, ok := logField(entry, cond.path)
+		if !ok || !f.matchCondition(cond, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// logField looks a field up by its literal key first, then as a dotted path
+func logField(entry map[string]interface{}, path string) (string, bool) {
+	value, ok := entry[path]
+	if !ok {
+		var current interface{} = entry
+		for _, key := range strings.Split(path, ".") {
+			m, isMap := current.(map[string]interface{})
+			if !isMap {
+				return "", false
+			}
+			if current, ok = m[key]; !ok {
+				return "", false
+			}
+		}
+		value = current
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "", true
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(raw), true
+	}
+}
+
+func (f *LogFilter) matchCondition(cond logFieldCondition, value string) bool {
+	if cond.op == "~" {
+		return cond.re.MatchString(value)
+	}
+	cmp := f.compare(value, cond.value)
+	switch cond.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	}
+	return false
+}
+
+// compare orders two values as log levels if both are level names, as numbers if both are numeric,
+// and as strings otherwise
+func (f *LogFilter) compare(a, b string) int {
+	la, okA := logLevels[strings.ToLower(a)]
+	lb, okB := logLevels[strings.ToLower(b)]
+	if okA && okB {
+		return la - lb
+	}
+	na, errA := strconv.ParseFloat(a, 64)
+	nb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return 0
+	}
+	if !f.caseSensitive {
+		a, b = strings.ToLower(a), strings.ToLower(b)
+	}
+	return strings.Compare(a, b)
+}
+
+// logFilterState tracks the context lines of one stream, like grep -B and -A
+type logFilterState struct {
+	filter *LogFilter
+	before []string
+	after  int
+}
+
+// process returns the lines to send for a log line, the line itself and any context lines before it
+func (s *logFilterState) process(filter *LogFilter, line string, timestamped bool) []string {
+	if filter != s.filter {
+		// The filter was changed mid-stream, context from the old filter does not apply
+		s.filter = filter
+		s.before = s.before[:0]
+		s.after = 0
+	}
+	if filter == nil {
+		return []string{line}
+	}
+
+	if filter.Match(line, timestamped) {
+		lines := append(s.before, line)
+		s.before = nil
+		s.after = filter.after
+		return lines
+	}
+	if s.after > 0 {
+		s.after--
+		return []string{line}
+	}
+	if filter.before > 0 {
+		if len(s.before) == filter.before {
+			s.before = append(s.before[:0], s.before[1:]...)
+		}
+		s.before = append(s.before, line)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package kube
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLogFieldCondition(t *testing.T) {
+	tests := []struct {
+		expr    string
+		path    string
+		op      string
+		value   string
+		wantErr bool
+	}{
+		{expr: "level>=warn", path: "level", op: ">=", value: "warn"},
+		{expr: "level<=info", path: "level", op: "<=", value: "info"},
+		{expr: "status!=200", path: "status", op: "!=", value: "200"},
+		{expr: "latency > 100", path: "latency", op: ">", value: "100"},
+		{expr: "req.path~^/api", path: "req.path", op: "~", value: "^/api"},
+		{expr: "msg=a=b", path: "msg", op: "=", value: "a=b"},
+		{expr: ">=warn", wantErr: true},
+		{expr: "level", wantErr: true},
+		{expr: "msg~(", wantErr: true},
+	}
+
+	f := &LogFilter{}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := f.parseCondition(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseCondition(%q) expected an error", tt.expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCondition(%q) returned error: %v", tt.expr, err)
+			}
+			if cond.path != tt.path || cond.op != tt.op || cond.value != tt.value {
+				t.Errorf("parseCondition(%q) = %q %q %q, expected %q %q %q",
+					tt.expr, cond.path, cond.op, cond.value, tt.path, tt.op, tt.value)
+			}
+		})
+	}
+}
+
+func TestLogFilterMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        LogFilterOptions
+		line        string
+		timestamped bool
+		expected    bool
+	}{
+		// Levels are ranked, so level>=warn also matches error
+		{
+			name:     "level error is at least warn",
+			opts:     LogFilterOptions{Fields: []string{"level>=warn"}},
+			line:     `{"level":"error","msg":"failed"}`,
+			expected: true,
+		},
+		{
+			name:     "level info is below warn",
+			opts:     LogFilterOptions{Fields: []string{"level>=warn"}},
+			line:     `{"level":"info","msg":"started"}`,
+			expected: false,
+		},
+		{
+			name:     "level names ignore case",
+			opts:     LogFilterOptions{Fields: []string{"level>=warn"}},
+			line:     `{"level":"WARN","msg":"slow"}`,
+			expected: true,
+		},
+		{
+			name:     "level above is not below",
+			opts:     LogFilterOptions{Fields: []string{"level<warn"}},
+			line:     `{"level":"error"}`,
+			expected: false,
+		},
+
+		// Numbers compare numerically, not as strings
+		{
+			name:     "number greater",
+			opts:     LogFilterOptions{Fields: []string{"latency>100"}},
+			line:     `{"latency":250}`,
+			expected: true,
+		},
+		{
+			name:     "number not compared as string",
+			opts:     LogFilterOptions{Fields: []string{"latency>100"}},
+			line:     `{"latency":99}`,
+			expected: false,
+		},
+		{
+			name:     "string equality ignores case",
+			opts:     LogFilterOptions{Fields: []string{"user=Alice"}},
+			line:     `{"user":"alice"}`,
+			expected: true,
+		},
+		{
+			name:     "string equality with case sensitivity",
+			opts:     LogFilterOptions{Fields: []string{"user=Alice"}, CaseSensitive: true},
+			line:     `{"user":"alice"}`,
+			expected: false,
+		},
+
+		// Dotted paths look into nested objects, a literal key wins
+		{
+			name:     "nested field",
+			opts:     LogFilterOptions{Fields: []string{"a.b=1"}},
+			line:     `{"a":{"b":1}}`,
+			expected: true,
+		},
+		{
+			name:     "nested field mismatch",
+			opts:     LogFilterOptions{Fields: []string{"a.b=1"}},
+			line:     `{"a":{"b":2}}`,
+			expected: false,
+		},
+		{
+			name:     "literal dotted key",
+			opts:     LogFilterOptions{Fields: []string{"a.b=1"}},
+			line:     `{"a.b":1}`,
+			expected: true,
+		},
+		{
+			name:     "path through a non-object",
+			opts:     LogFilterOptions{Fields: []string{"a.b=1"}},
+			line:     `{"a":"b"}`,
+			expected: false,
+		},
+		{
+			name:     "missing field",
+			opts:     LogFilterOptions{Fields: []string{"trace_id!=abc"}},
+			line:     `{"msg":"no trace"}`,
+			expected: false,
+		},
+
+		// Regex conditions
+		{
+			name:     "regex match",
+			opts:     LogFilterOptions{Fields: []string{"path~^/api/v[0-9]+/"}},
+			line:     `{"path":"/api/v1/pods"}`,
+			expected: true,
+		},
+		{
+			name:     "regex no match",
+			opts:     LogFilterOptions{Fields: []string{"path~^/api/v[0-9]+/"}},
+			line:     `{"path":"/healthz"}`,
+			expected: false,
+		},
+
+		// Field filters only match JSON objects, after the timestamp of the line
+		{
+			name:     "plain text never matches fields",
+			opts:     LogFilterOptions{Fields: []string{"level>=warn"}},
+			line:     `level=error msg=failed`,
+			expected: false,
+		},
+		{
+			name:        "timestamped JSON line",
+			opts:        LogFilterOptions{Fields: []string{"level>=warn"}},
+			line:        `2024-03-01T12:00:00.123456789Z {"level":"error"}`,
+			timestamped: true,
+			expected:    true,
+		},
+		{
+			name:     "include and exclude",
+			opts:     LogFilterOptions{Include: "error", Exclude: "ignored"},
+			line:     `ERROR: ignored failure`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewLogFilter(tt.opts)
+			if err != nil {
+				t.Fatalf("NewLogFilter(%+v) returned error: %v", tt.opts, err)
+			}
+			if result := f.Match(tt.line, tt.timestamped); result != tt.expected {
+				t.Errorf("Match(%q) with %+v = %v, expected %v", tt.line, tt.opts, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLogFilterStateContext(t *testing.T) {
+	first, err := NewLogFilter(LogFilterOptions{Include: "match", Before: 1, After: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewLogFilter(LogFilterOptions{Include: "other", Before: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		filter   *LogFilter
+		line     string
+		expected []string
+	}{
+		{first, "a", nil},
+		{first, "b", nil},
+		{first, "match 1", []string{"b", "match 1"}},
+		{first, "c", []string{"c"}},
+		{first, "d", nil},
+		{first, "e", nil},
+		{first, "match 2", []string{"e", "match 2"}},
+		// The swap drops the pending after line and the before lines of the old filter
+		{second, "f", nil},
+		{second, "other", []string{"f", "other"}},
+		{second, "g", nil},
+		{second, "h", nil},
+		{second, "i", nil},
+		{second, "other", []string{"h", "i", "other"}},
+		// Without a filter every line is sent
+		{nil, "j", []string{"j"}},
+	}
+
+	var state logFilterState
+	for i, step := range steps {
+		result := state.process(step.filter, step.line, false)
+		if !reflect.DeepEqual(result, step.expected) {
+			t.Errorf("step %d: process(%q) = %q, expected %q", i, step.line, result, step.expected)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForward relays one TCP connection to a port of a pod through the portforward subresource,
+// conn carries the raw bytes in both directions. It returns when either side closes.
+func PortForward(ctx context.Context, client *K8sClient, namespace, podName string, port int32, conn io.ReadWriter) error {
+	req := client.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("portforward")
+
+	transport, upgrader, err := spdy.RoundTripperFor(client.Configuration)
+	if err != nil {
+		return err
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+	streamConn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade connection: %w", err)
+	}
+	defer func() {
+		_ = streamConn.Close()
+	}()
+
+	// Every forwarded connection needs an error and a data stream with the same request ID
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(int(port)))
+	headers.Set(corev1.PortForwardRequestIDHeader, "0")
+	errorStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		return fmt.Errorf("failed to create error stream: %w", err)
+	}
+	// The error stream is only read
+	_ = errorStream.Close()
+	errCh := make(chan error, 1)
+	go func() {
+		message, err := io.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			errCh <- fmt.Errorf("failed to read from error stream: %w", err)
+		case len(message) > 0:
+			errCh <- fmt.Errorf("port forward to %s/%s:%d failed: %s", namespace, podName, port, message)
+		}
+		close(errCh)
+	}()
+
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		return fmt.Errorf("failed to create data stream: %w", err)
+	}
+
+	remoteDone := make(chan struct{})
+	localDone := make(chan struct{})
+	go func() {
+		// The pod closed the connection
+		_, _ = io.Copy(conn, dataStream)
+		close(remoteDone)
+	}()
+	go func() {
+		// The client closed the connection, half close so the pod sees EOF
+		_, _ = io.Copy(dataStream, conn)
+		_ = dataStream.Close()
+		close(localDone)
+	}()
+
+	select {
+	case <-remoteDone:
+		// The pod reports why it closed the connection, e.g. nothing listens on the port
+		return <-errCh
+	case <-localDone:
+	case <-ctx.Done():
+	}
+	select {
+	case err := <-errCh:
+		return err
+	default:
+		return nil
+	}
+}
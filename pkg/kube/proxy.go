@@ -1,20 +1,40 @@
 package kube
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xhilmi/kubedash/pkg/common"
+	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 )
 
+// maxRewriteBodySize limits the HTML pages whose links are rewritten, larger pages pass unchanged
+const maxRewriteBodySize = 8 << 20
+
+// HandleProxy proxies a request of any method to a pod or service through the API server proxy.
+// WebSocket and other upgrade requests are passed through. The API server rewrites redirects
+// and HTML links to its own /api/v1/namespaces/.../proxy path, which matches the Kite route,
+// so they only need the API server host and path removed and common.Base added.
 func HandleProxy(c *gin.Context, client *K8sClient, kind, namespace, name, proxyPath string) {
 	restConfig := rest.CopyConfig(client.Configuration)
-	httpClient, err := rest.HTTPClientFor(restConfig)
+	if httpstream.IsUpgradeRequest(c.Request) {
+		// Upgrades need HTTP/1.1, HTTP/2 has no Connection: Upgrade
+		restConfig.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	}
+	transport, err := rest.TransportFor(restConfig)
 	if err != nil {
 		klog.Errorf("failed to build kubernetes http client: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to initialize kubernetes client"})
@@ -25,34 +45,108 @@ func HandleProxy(c *gin.Context, client *K8sClient, kind, namespace, name, proxy
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, targetURL, c.Request.Body)
+	target, err := url.Parse(targetURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create proxy request"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	req.Header = cloneHeader(c.Request.Header)
-	req.Header.Del("Authorization")
-	req.Header.Del("Cookie")
 
-	resp, err := httpClient.Do(req)
+	proxyPrefix := fmt.Sprintf("/api/v1/namespaces/%s/%s/%s/proxy", namespace, kind, name)
+	// The API server may be served under a path, e.g. behind Rancher
+	apiPath, _, _ := strings.Cut(target.Path, "/api/v1/namespaces/")
+	rewriter := &proxyRewriter{
+		apiHost: target.Host,
+		from:    strings.TrimSuffix(apiPath, "/") + proxyPrefix,
+		to:      strings.TrimSuffix(common.Base, "/") + proxyPrefix,
+	}
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.Out.URL = target
+			r.Out.Host = target.Host
+			r.Out.Header.Del("Authorization")
+			r.Out.Header.Del("Cookie")
+			if rewriter.from != rewriter.to {
+				// Let the transport decompress, so HTML pages can be rewritten
+				r.Out.Header.Del("Accept-Encoding")
+			}
+		},
+		Transport:      transport,
+		FlushInterval:  -1,
+		ModifyResponse: rewriter.modifyResponse,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			klog.Errorf("proxy request failed: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "upstream request failed"})
+		},
+	}
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
 
+// proxyRewriter maps the API server proxy URLs in responses to the Kite proxy route
+type proxyRewriter struct {
+	apiHost string
+	from    string // proxy path as the API server writes it
+	to      string // proxy path of Kite
+}
+
+func (p *proxyRewriter) modifyResponse(resp *http.Response) error {
+	if location := resp.Header.Get("Location"); location != "" {
+		resp.Header.Set("Location", p.rewriteLocation(location))
+	}
+	if p.from == p.to || resp.StatusCode == http.StatusSwitchingProtocols {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" || resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRewriteBodySize+1))
 	if err != nil {
-		klog.Errorf("proxy request failed: %v", err)
-		c.JSON(http.StatusBadGateway, gin.H{"error": "upstream request failed"})
-		return
+		return err
+	}
+	if len(body) > maxRewriteBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+	_ = resp.Body.Close()
+	body = p.rewriteHTML(body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
 
-	copyHeader(c.Writer.Header(), resp.Header)
-	c.Writer.WriteHeader(resp.StatusCode)
-	if _, err := io.Copy(c.Writer, resp.Body); err != nil {
-		if !errors.Is(err, context.Canceled) {
-			klog.Errorf("failed to write proxy response: %v", err)
+// rewriteLocation turns redirects to the API server proxy into redirects to the Kite proxy route
+func (p *proxyRewriter) rewriteLocation(location string) string {
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	if u.IsAbs() {
+		if u.Host != p.apiHost {
+			// Redirect to another site
+			return location
 		}
+		u.Scheme, u.Host, u.User = "", "", nil
+	}
+	if rest, ok := strings.CutPrefix(u.Path, p.from); ok {
+		u.Path = p.to + rest
+		u.RawPath = ""
 	}
+	return u.String()
+}
+
+// rewriteHTML maps the links the API server rewrote to its proxy path, base href included.
+// Only links at the start of an attribute or url() are matched, so rewritten ones are not again.
+func (p *proxyRewriter) rewriteHTML(body []byte) []byte {
+	pattern := regexp.MustCompile(`(["'(=]\s*)` + regexp.QuoteMeta(p.from))
+	return pattern.ReplaceAll(body, []byte("${1}"+p.to))
 }
 
 func buildProxyURL(host, kind, namespace, name, path, rawQuery string) (string, error) {
@@ -72,21 +166,3 @@ func buildProxyURL(host, kind, namespace, name, path, rawQuery string) (string,
 	u.RawQuery = query.Encode()
 	return u.String(), nil
 }
-
-func cloneHeader(h http.Header) http.Header {
-	cloned := make(http.Header, len(h))
-	for k, values := range h {
-		for _, v := range values {
-			cloned.Add(k, v)
-		}
-	}
-	return cloned
-}
-
-func copyHeader(dst, src http.Header) {
-	for k, values := range src {
-		for _, v := range values {
-			dst.Add(k, v)
-		}
-	}
-}
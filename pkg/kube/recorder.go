@@ -0,0 +1,103 @@
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"k8s.io/klog/v2"
+)
+
+// CastHeader is the first line of an asciinema cast v2 file
+type CastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// CastRecorder writes a terminal session in asciinema cast v2 format, a header line followed
+// by one [seconds, code, data] event per line: "o" for output, "i" for input and "r" for resize.
+// Recording errors are logged and stop the recording, they never break the session.
+type CastRecorder struct {
+	mu      sync.Mutex
+	w       io.Writer
+	start   time.Time
+	pending []byte // incomplete UTF-8 sequence at the end of the last output
+	failed  bool
+}
+
+// NewCastRecorder writes the cast header and returns a recorder for the events
+func NewCastRecorder(w io.Writer, width, height int, title string) (*CastRecorder, error) {
+	start := time.Now()
+	header, err := json.Marshal(CastHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", header); err != nil {
+		return nil, err
+	}
+	return &CastRecorder{w: w, start: start}, nil
+}
+
+// Output records terminal output, a multi-byte character split across writes is kept for the next one
+func (r *CastRecorder) Output(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buf := append(r.pending, p...)
+	cut := len(buf)
+	for i := 1; i <= utf8.UTFMax && i <= len(buf); i++ {
+		if utf8.RuneStart(buf[len(buf)-i]) {
+			if !utf8.FullRune(buf[len(buf)-i:]) {
+				cut = len(buf) - i
+			}
+			break
+		}
+	}
+	r.pending = append([]byte(nil), buf[cut:]...)
+	if cut > 0 {
+		r.event("o", string(buf[:cut]))
+	}
+}
+
+// Input records what the user typed
+func (r *CastRecorder) Input(data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.event("i", data)
+}
+
+// Resize records a terminal size change as COLSxROWS
+func (r *CastRecorder) Resize(cols, rows uint16) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.event("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// event writes one event line, the caller must hold r.mu
+func (r *CastRecorder) event(code, data string) {
+	if r.failed {
+		return
+	}
+	line, err := json.Marshal([]interface{}{time.Since(r.start).Seconds(), code, data})
+	if err == nil {
+		_, err = fmt.Fprintf(r.w, "%s\n", line)
+	}
+	if err != nil {
+		klog.Errorf("Failed to write terminal recording, recording stopped: %v", err)
+		r.failed = true
+	}
+}
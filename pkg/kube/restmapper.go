@@ -0,0 +1,102 @@
+package kube
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/klog/v2"
+)
+
+// minResetInterval limits how often a lookup miss may drop the discovery cache,
+// so requests for unknown kinds do not hammer the API server
+const minResetInterval = 10 * time.Second
+
+// resourceNameAliases maps resources to the names Kite uses for them in routes and RBAC rules
+var resourceNameAliases = map[schema.GroupResource]string{
+	{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}: "crds",
+}
+
+// RESTMapper maps between kinds and resources using cached discovery information.
+// The cache is dropped when CRDs change, and a lookup miss triggers a rediscovery.
+type RESTMapper struct {
+	*restmapper.DeferredDiscoveryRESTMapper
+
+	mu        sync.Mutex
+	lastReset time.Time
+}
+
+// NewRESTMapper creates a RESTMapper backed by an in-memory discovery cache
+func NewRESTMapper(client discovery.DiscoveryInterface) *RESTMapper {
+	return &RESTMapper{
+		DeferredDiscoveryRESTMapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client)),
+	}
+}
+
+// Reset drops the cached discovery information, it is reloaded on the next lookup
+func (m *RESTMapper) Reset() {
+	m.mu.Lock()
+	m.lastReset = time.Now()
+	m.mu.Unlock()
+	m.DeferredDiscoveryRESTMapper.Reset()
+}
+
+// resetOnMiss resets the cache after a lookup miss, unless it was refreshed very recently
+func (m *RESTMapper) resetOnMiss(err error) bool {
+	if !meta.IsNoMatchError(err) {
+		return false
+	}
+	m.mu.Lock()
+	if time.Since(m.lastReset) < minResetInterval {
+		m.mu.Unlock()
+		return false
+	}
+	m.mu.Unlock()
+	klog.V(2).Infof("Refreshing discovery cache after lookup miss: %v", err)
+	m.Reset()
+	return true
+}
+
+// MappingFor returns the REST mapping of a kind, rediscovering once if the kind is unknown
+func (m *RESTMapper) MappingFor(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := m.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil && m.resetOnMiss(err) {
+		mapping, err = m.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
+
+// KindForResource returns the preferred kind of a resource, rediscovering once if the resource is unknown
+func (m *RESTMapper) KindForResource(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	gvk, err := m.KindFor(gvr)
+	if err != nil && m.resetOnMiss(err) {
+		gvk, err = m.KindFor(gvr)
+	}
+	return gvk, err
+}
+
+// ResourceName returns the name Kite uses for a resource in routes and RBAC rules:
+// the plural for types known to the client scheme, <plural>.<group> for custom resources
+func ResourceName(gvr schema.GroupVersionResource) string {
+	if alias, ok := resourceNameAliases[gvr.GroupResource()]; ok {
+		return alias
+	}
+	if gvr.Group == "" || runtimeScheme.IsGroupRegistered(gvr.Group) {
+		return gvr.Resource
+	}
+	return gvr.Resource + "." + gvr.Group
+}
+
+// ParseResourceName is the inverse of ResourceName, it accepts <plural> or <plural>.<group>
+func ParseResourceName(name string) schema.GroupResource {
+	for gr, alias := range resourceNameAliases {
+		if alias == name {
+			return gr
+		}
+	}
+	return schema.ParseGroupResource(name)
+}
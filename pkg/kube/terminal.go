@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/websocket"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/klog/v2"
 )
@@ -31,8 +31,12 @@ type TerminalSession struct {
 	namespace string
 	podName   string
 	container string
+	recorder  *CastRecorder
 
 	lastHeartbeat time.Time // Track last heartbeat for ping/pong
+
+	idleTimeout  time.Duration
+	lastActivity atomic.Int64 // Unix nanoseconds of the last input
 }
 
 func NewTerminalSession(client *K8sClient, conn *websocket.Conn, namespace, podName, container string) *TerminalSession {
@@ -46,26 +50,26 @@ func NewTerminalSession(client *K8sClient, conn *websocket.Conn, namespace, podN
 	}
 }
 
+// SetRecorder records the session, it must be called before Start
+func (session *TerminalSession) SetRecorder(recorder *CastRecorder) {
+	session.recorder = recorder
+}
+
+// SetIdleTimeout closes the session when no input arrives for d, it must be called before Start.
+// Pings from the browser keep the connection alive but do not count as activity.
+func (session *TerminalSession) SetIdleTimeout(d time.Duration) {
+	session.idleTimeout = d
+}
+
 func (session *TerminalSession) Start(ctx context.Context, subResource string) error {
-	req := session.k8sClient.ClientSet.CoreV1().RESTClient().Post().
-		Resource("pods").
-		Name(session.podName).
-		Namespace(session.namespace).
-		SubResource(subResource)
-
-	// Set up exec parameters
-	req.VersionedParams(&corev1.PodExecOptions{
+	exec, err := newExecutor(session.k8sClient, session.namespace, session.podName, subResource, &corev1.PodExecOptions{
 		Container: session.container,
 		Command:   []string{"sh", "-c", "bash || sh"},
 		Stdin:     true,
 		Stdout:    true,
 		Stderr:    true,
 		TTY:       true,
-	}, scheme.ParameterCodec)
-
-	// TODO: use NewWebSocketExecutor
-	exec, err := remotecommand.NewSPDYExecutor(session.k8sClient.Configuration, "POST", req.URL())
-
+	})
 	if err != nil {
 		log.Printf("Failed to create executor: %v", err)
 		session.SendErrorMessage(fmt.Sprintf("Failed to create executor: %v", err))
@@ -109,10 +113,17 @@ func (session *TerminalSession) Read(p []byte) (int, error) {
 
 	switch msg.Type {
 	case "stdin":
+		session.lastActivity.Store(time.Now().UnixNano())
+		if session.recorder != nil {
+			session.recorder.Input(msg.Data)
+		}
 		data := []byte(msg.Data)
 		return copy(p, data), nil
 	case "resize":
 		if msg.Rows > 0 && msg.Cols > 0 {
+			if session.recorder != nil {
+				session.recorder.Resize(msg.Cols, msg.Rows)
+			}
 			select {
 			case session.sizeChan <- &remotecommand.TerminalSize{
 				Width:  msg.Cols,
@@ -131,6 +142,9 @@ func (session *TerminalSession) Read(p []byte) (int, error) {
 }
 
 func (session *TerminalSession) Write(p []byte) (int, error) {
+	if session.recorder != nil {
+		session.recorder.Output(p)
+	}
 	msg := TerminalMessage{
 		Type: "stdout",
 		Data: string(p),
@@ -164,6 +178,7 @@ func (session *TerminalSession) SendErrorMessage(errMsg string) {
 
 func (session *TerminalSession) checkHeartbeat(ctx context.Context) {
 	session.lastHeartbeat = time.Now()
+	session.lastActivity.Store(time.Now().UnixNano())
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
@@ -178,6 +193,14 @@ func (session *TerminalSession) checkHeartbeat(ctx context.Context) {
 				}
 				return
 			}
+			if session.idleTimeout > 0 && time.Since(time.Unix(0, session.lastActivity.Load())) > session.idleTimeout {
+				klog.Infof("Closing terminal session to %s/%s after %s of inactivity", session.namespace, session.podName, session.idleTimeout)
+				session.SendErrorMessage(fmt.Sprintf("Session closed after %s of inactivity", session.idleTimeout))
+				if err := session.conn.Close(); err != nil {
+					klog.Errorf("WebSocket close error: %v", err)
+				}
+				return
+			}
 		}
 	}
 }
@@ -27,6 +27,7 @@ func RBACMiddleware() gin.HandlerFunc {
 		if strings.HasSuffix(path, "/restart") || strings.HasSuffix(path, "/scale") || 
 		   strings.HasSuffix(path, "/edit") || strings.HasSuffix(path, "/rollback") || 
 		   strings.HasSuffix(path, "/suspend") || strings.HasSuffix(path, "/resume") ||
+		   strings.HasSuffix(path, "/pause") || strings.HasSuffix(path, "/trigger") ||
 		   strings.Contains(path, "/helm/") || strings.Contains(path, "/flux/") ||
 		   strings.HasSuffix(path, "/history") {
 			// Skip middleware RBAC, handler will check specific verb
@@ -36,12 +37,15 @@ func RBACMiddleware() gin.HandlerFunc {
 		}
 		
 		verbs := method2verb(c.Request.Method)
+		if verbs == string(common.VerbGet) && strings.HasSuffix(path, "/watch") {
+			verbs = string(common.VerbWatch)
+		}
 		ns, resource := url2namespaceresource(path)
 		if ns == "" || resource == "" {
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid resource URL"})
 			return
 		}
-		if resource == "namespaces" && verbs == "get" {
+		if resource == "namespaces" && (verbs == "get" || verbs == "watch") {
 			// if user has roles, allow access to list namespaces resource
 			// don't worry about security here, we will filter namespaces in the list namespace handler
 			// this is just to allow users to list namespaces they have access to
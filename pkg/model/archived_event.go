@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ArchivedEvent is a Kubernetes event kept beyond the API server TTL. Each event object is
+// stored once per cluster by its UID, repeated occurrences update its count and last timestamp.
+type ArchivedEvent struct {
+	Model
+	ClusterName string `json:"clusterName" gorm:"type:varchar(100);not null;uniqueIndex:idx_archived_event_uid;index:idx_archived_event_object"`
+	UID         string `json:"uid" gorm:"type:varchar(64);not null;uniqueIndex:idx_archived_event_uid"`
+	Namespace   string `json:"namespace" gorm:"type:varchar(100);index:idx_archived_event_object"`
+	Name        string `json:"name" gorm:"type:varchar(255)"`
+
+	InvolvedKind       string `json:"involvedKind" gorm:"type:varchar(100);index:idx_archived_event_object"`
+	InvolvedAPIVersion string `json:"involvedApiVersion" gorm:"type:varchar(100)"`
+	InvolvedName       string `json:"involvedName" gorm:"type:varchar(255);index:idx_archived_event_object"`
+	InvolvedUID        string `json:"involvedUid" gorm:"type:varchar(64)"`
+
+	Type    string `json:"type" gorm:"type:varchar(20)"`
+	Reason  string `json:"reason" gorm:"type:varchar(128)"`
+	Message string `json:"message" gorm:"type:text"`
+	Source  string `json:"source" gorm:"type:varchar(255)"`
+	Count   int32  `json:"count"`
+
+	FirstTimestamp time.Time `json:"firstTimestamp"`
+	LastTimestamp  time.Time `json:"lastTimestamp" gorm:"not null;index"`
+}
+
+// UpsertArchivedEvents stores new events and updates the ones already archived
+func UpsertArchivedEvents(events []ArchivedEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "cluster_name"}, {Name: "uid"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at", "type", "message", "source", "count", "first_timestamp", "last_timestamp",
+		}),
+	}).CreateInBatches(events, 100).Error
+}
+
+// ListArchivedEvents returns the archived events of an involved object, the most recent first.
+// An empty namespace is a cluster-scoped object, its events may be stored in any namespace.
+func ListArchivedEvents(clusterName, namespace, kind, apiVersion, name string) ([]ArchivedEvent, error) {
+	var events []ArchivedEvent
+	query := DB.Where("cluster_name = ? AND involved_kind = ? AND involved_api_version = ? AND involved_name = ?",
+		clusterName, kind, apiVersion, name)
+	if namespace != "" {
+		query = query.Where("namespace = ?", namespace)
+	}
+	err := query.Order("last_timestamp desc").Find(&events).Error
+	return events, err
+}
+
+// DeleteArchivedEventsBefore deletes the archived events of all clusters last seen before t
+func DeleteArchivedEventsBefore(t time.Time) (int64, error) {
+	result := DB.Where("last_timestamp < ?", t).Delete(&ArchivedEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// TrimArchivedEvents keeps the maxRows most recently seen archived events of each cluster
+func TrimArchivedEvents(maxRows int) (int64, error) {
+	var clusters []string
+	if err := DB.Model(&ArchivedEvent{}).Distinct("cluster_name").Pluck("cluster_name", &clusters).Error; err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, clusterName := range clusters {
+		// The newest event to remove, everything seen before it goes as well
+		var oldest ArchivedEvent
+		err := DB.Where("cluster_name = ?", clusterName).
+			Order("last_timestamp desc, id desc").
+			Offset(maxRows).Limit(1).
+			Find(&oldest).Error
+		if err != nil {
+			return deleted, err
+		}
+		if oldest.ID == 0 {
+			continue
+		}
+		result := DB.Where("cluster_name = ? AND (last_timestamp < ? OR (last_timestamp = ? AND id <= ?))",
+			clusterName, oldest.LastTimestamp, oldest.LastTimestamp, oldest.ID).
+			Delete(&ArchivedEvent{})
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+	}
+	return deleted, nil
+}
@@ -1,5 +1,12 @@
 package model
 
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xhilmi/kubedash/pkg/common"
+)
+
 type Cluster struct {
 	Model
 	Name          string       `json:"name" gorm:"type:varchar(100);uniqueIndex;not null"`
@@ -9,6 +16,26 @@ type Cluster struct {
 	InCluster     bool         `json:"in_cluster" gorm:"type:boolean;default:false"`
 	IsDefault     bool         `json:"is_default" gorm:"type:boolean;default:false"`
 	Enable        bool         `json:"enable" gorm:"type:boolean;default:true"`
+
+	// RecordTerminal records pod and node terminal sessions of this cluster
+	RecordTerminal bool `json:"record_terminal" gorm:"type:boolean;default:false"`
+	// NodeTerminalConfig is a JSON common.NodeTerminalConfig overriding the node terminal defaults
+	NodeTerminalConfig string `json:"node_terminal_config,omitempty" gorm:"type:text"`
+	// ArchiveEvents stores the Kubernetes events of this cluster beyond the API server TTL
+	ArchiveEvents bool `json:"archive_events" gorm:"type:boolean;default:false"`
+}
+
+// NodeTerminal returns the node terminal settings of the cluster, the defaults merged with its overrides
+func (c *Cluster) NodeTerminal() (common.NodeTerminalConfig, error) {
+	config := common.DefaultNodeTerminalConfig()
+	if c.NodeTerminalConfig == "" {
+		return config, nil
+	}
+	var override common.NodeTerminalConfig
+	if err := json.Unmarshal([]byte(c.NodeTerminalConfig), &override); err != nil {
+		return config, fmt.Errorf("invalid node terminal config of cluster %s: %w", c.Name, err)
+	}
+	return config.Merge(override), nil
 }
 
 func AddCluster(cluster *Cluster) error {
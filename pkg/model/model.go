@@ -84,6 +84,8 @@ func InitDB() {
 		Role{},
 		RoleAssignment{},
 		ResourceHistory{},
+		TerminalRecording{},
+		ArchivedEvent{},
 	}
 	for _, model := range models {
 		err = DB.AutoMigrate(model)
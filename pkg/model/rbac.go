@@ -50,7 +50,7 @@ var (
 		Clusters:    []string{"*"},
 		Resources:   []string{"*"},
 		Namespaces:  []string{"*"},
-		Verbs:       []string{"get", "log"},
+		Verbs:       []string{"get", "watch", "log"},
 	}
 )
 
@@ -0,0 +1,103 @@
+package model
+
+import "time"
+
+const (
+	TerminalRecordingKindPod  = "pod"
+	TerminalRecordingKindNode = "node"
+)
+
+// TerminalRecording describes a recorded terminal session, the asciinema cast itself is stored
+// as a file. The operator is kept by name as well, so recordings outlive deleted users.
+type TerminalRecording struct {
+	Model
+	ClusterName string `json:"clusterName" gorm:"type:varchar(100);not null;index"`
+	Kind        string `json:"kind" gorm:"type:varchar(20);not null;index"` // pod or node
+	Namespace   string `json:"namespace" gorm:"type:varchar(100)"`
+	PodName     string `json:"podName" gorm:"type:varchar(255);index"`
+	Container   string `json:"container" gorm:"type:varchar(255)"`
+	NodeName    string `json:"nodeName,omitempty" gorm:"type:varchar(255);index"`
+
+	OperatorID   uint   `json:"operatorId" gorm:"not null;index"`
+	OperatorName string `json:"operatorName" gorm:"type:varchar(100);not null;index"`
+
+	StartedAt time.Time  `json:"startedAt" gorm:"not null;index"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	Size      int64      `json:"size"`
+	FileName  string     `json:"-" gorm:"type:varchar(255);not null"`
+}
+
+// TerminalRecordingFilter narrows down ListTerminalRecordings, empty fields match everything
+type TerminalRecordingFilter struct {
+	ClusterName  string
+	Kind         string
+	OperatorName string
+	Namespace    string
+	PodName      string
+	NodeName     string
+}
+
+func AddTerminalRecording(recording *TerminalRecording) error {
+	return DB.Create(recording).Error
+}
+
+// FinishTerminalRecording stores the end time and the final size of a recording
+func FinishTerminalRecording(recording *TerminalRecording, endedAt time.Time, size int64) error {
+	return DB.Model(recording).Updates(map[string]interface{}{
+		"ended_at": endedAt,
+		"size":     size,
+	}).Error
+}
+
+func GetTerminalRecordingByID(id uint) (*TerminalRecording, error) {
+	var recording TerminalRecording
+	if err := DB.First(&recording, id).Error; err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+// ListTerminalRecordings returns recordings newest first with pagination. If limit is 0, defaults to 20.
+func ListTerminalRecordings(filter TerminalRecordingFilter, limit, offset int) (recordings []TerminalRecording, total int64, err error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	query := DB.Model(&TerminalRecording{})
+	for column, value := range map[string]string{
+		"cluster_name":  filter.ClusterName,
+		"kind":          filter.Kind,
+		"operator_name": filter.OperatorName,
+		"namespace":     filter.Namespace,
+		"pod_name":      filter.PodName,
+		"node_name":     filter.NodeName,
+	} {
+		if value != "" {
+			query = query.Where(column+" = ?", value)
+		}
+	}
+	if err = query.Count(&total).Error; err != nil {
+		return
+	}
+	err = query.Order("started_at desc").Limit(limit).Offset(offset).Find(&recordings).Error
+	return
+}
+
+// DeleteTerminalRecordingsBefore deletes the recordings started before t and returns them,
+// so the caller can remove their files
+func DeleteTerminalRecordingsBefore(t time.Time) ([]TerminalRecording, error) {
+	var recordings []TerminalRecording
+	if err := DB.Where("started_at < ?", t).Find(&recordings).Error; err != nil {
+		return nil, err
+	}
+	if len(recordings) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, 0, len(recordings))
+	for _, r := range recordings {
+		ids = append(ids, r.ID)
+	}
+	if err := DB.Delete(&TerminalRecording{}, ids).Error; err != nil {
+		return nil, err
+	}
+	return recordings, nil
+}
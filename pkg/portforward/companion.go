@@ -0,0 +1,242 @@
+// Package portforward implements the port-forward companion mode of the kite binary.
+// It exposes pod and service ports of a cluster as local ports, tunnelling every
+// connection through the port-forward WebSocket of a Kite server:
+//
+//	kite port-forward -server https://kite.example.com -token $KITE_API_KEY default svc/postgres 5432
+package portforward
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/net/websocket"
+)
+
+// frame is a WebSocket message together with its frame type
+type frame struct {
+	payloadType byte
+	data        []byte
+}
+
+var frameCodec = websocket.Codec{
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		f := v.(*frame)
+		f.payloadType = payloadType
+		f.data = data
+		return nil
+	},
+}
+
+// statusMessage is a text frame sent by the server
+type statusMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+type forwarder struct {
+	server   *url.URL
+	token    string
+	cluster  string
+	insecure bool
+
+	namespace string
+	kind      string
+	name      string
+}
+
+// portMapping is a local port and the pod or service port it forwards to
+type portMapping struct {
+	local  string
+	remote string
+}
+
+// Run parses the companion arguments and forwards until interrupted
+func Run(args []string) error {
+	fs := flag.NewFlagSet("port-forward", flag.ContinueOnError)
+	server := fs.String("server", os.Getenv("KITE_SERVER"), "Kite URL, including KITE_BASE if set (env KITE_SERVER)")
+	token := fs.String("token", os.Getenv("KITE_API_KEY"), "Kite API key (env KITE_API_KEY)")
+	clusterName := fs.String("cluster", "", "Cluster name, the default cluster if empty")
+	address := fs.String("address", "127.0.0.1", "Local address to listen on")
+	insecure := fs.Bool("insecure-skip-tls-verify", false, "Skip verifying the TLS certificate of the Kite server")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kite port-forward [flags] NAMESPACE pods/NAME|services/NAME [LOCAL_PORT:]REMOTE_PORT...\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 3 {
+		fs.Usage()
+		return errors.New("namespace, target and at least one port are required")
+	}
+	if *server == "" || *token == "" {
+		return errors.New("-server and -token are required")
+	}
+
+	serverURL, err := url.Parse(strings.TrimSuffix(*server, "/"))
+	if err != nil || (serverURL.Scheme != "http" && serverURL.Scheme != "https") {
+		return fmt.Errorf("invalid server URL %q", *server)
+	}
+	kind, name, err := parseTarget(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	f := &forwarder{
+		server:    serverURL,
+		token:     *token,
+		cluster:   *clusterName,
+		insecure:  *insecure,
+		namespace: fs.Arg(0),
+		kind:      kind,
+		name:      name,
+	}
+
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}()
+	for _, arg := range fs.Args()[2:] {
+		mapping, err := parsePortMapping(arg)
+		if err != nil {
+			return err
+		}
+		l, err := net.Listen("tcp", net.JoinHostPort(*address, mapping.local))
+		if err != nil {
+			return fmt.Errorf("failed to listen on port %s: %w", mapping.local, err)
+		}
+		listeners = append(listeners, l)
+		fmt.Fprintf(os.Stderr, "Forwarding from %s -> %s/%s:%s\n", l.Addr(), kind, name, mapping.remote)
+		go f.serve(l, mapping.remote)
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	return nil
+}
+
+// parseTarget accepts the resource names and short names kubectl accepts
+func parseTarget(target string) (string, string, error) {
+	kind, name, ok := strings.Cut(target, "/")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid target %q, expected pods/NAME or services/NAME", target)
+	}
+	switch kind {
+	case "pods", "pod", "po":
+		return "pods", name, nil
+	case "services", "service", "svc":
+		return "services", name, nil
+	}
+	return "", "", fmt.Errorf("invalid target %q, expected pods/NAME or services/NAME", target)
+}
+
+// parsePortMapping parses REMOTE, LOCAL:REMOTE or :REMOTE for a random local port.
+// The remote port may be a port name, which then needs an explicit local port.
+func parsePortMapping(arg string) (portMapping, error) {
+	local, remote, ok := strings.Cut(arg, ":")
+	if !ok {
+		local, remote = arg, arg
+	}
+	if remote == "" {
+		return portMapping{}, fmt.Errorf("invalid port mapping %q", arg)
+	}
+	if local == "" {
+		local = "0"
+	}
+	if p, err := strconv.ParseUint(local, 10, 16); err != nil || (p == 0 && local != "0") {
+		return portMapping{}, fmt.Errorf("invalid local port in %q", arg)
+	}
+	return portMapping{local: local, remote: remote}, nil
+}
+
+func (f *forwarder) serve(l net.Listener, remote string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			if err := f.forward(conn, remote); err != nil {
+				fmt.Fprintf(os.Stderr, "Forwarding %s to port %s failed: %v\n", conn.RemoteAddr(), remote, err)
+			}
+		}()
+	}
+}
+
+// forward tunnels one local connection through its own WebSocket
+func (f *forwarder) forward(conn net.Conn, remote string) error {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	wsURL := *f.server
+	if wsURL.Scheme == "https" {
+		wsURL.Scheme = "wss"
+	} else {
+		wsURL.Scheme = "ws"
+	}
+	wsURL.Path += fmt.Sprintf("/api/v1/portforward/%s/%s/%s/ws",
+		url.PathEscape(f.namespace), f.kind, url.PathEscape(f.name))
+	wsURL.RawQuery = url.Values{"port": {remote}}.Encode()
+
+	config, err := websocket.NewConfig(wsURL.String(), f.server.String())
+	if err != nil {
+		return err
+	}
+	config.Header.Set("Authorization", f.token)
+	if f.cluster != "" {
+		config.Header.Set("x-cluster-name", f.cluster)
+	}
+	if f.insecure {
+		config.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+
+	var once sync.Once
+	closeAll := func() {
+		once.Do(func() {
+			_ = ws.Close()
+			_ = conn.Close()
+		})
+	}
+	defer closeAll()
+
+	go func() {
+		_, _ = io.Copy(ws, conn)
+		closeAll()
+	}()
+	for {
+		var fr frame
+		if err := frameCodec.Receive(ws, &fr); err != nil {
+			return nil
+		}
+		if fr.payloadType == websocket.BinaryFrame {
+			if _, err := conn.Write(fr.data); err != nil {
+				return nil
+			}
+			continue
+		}
+		var msg statusMessage
+		if err := json.Unmarshal(fr.data, &msg); err == nil && msg.Type == "error" {
+			return errors.New(msg.Data)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package portforward
+
+import "testing"
+
+func TestParsePortMapping(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    portMapping
+		wantErr bool
+	}{
+		{arg: "5432", want: portMapping{local: "5432", remote: "5432"}},
+		{arg: "8080:80", want: portMapping{local: "8080", remote: "80"}},
+		{arg: ":80", want: portMapping{local: "0", remote: "80"}},
+		{arg: "8080:http", want: portMapping{local: "8080", remote: "http"}},
+		{arg: "http", wantErr: true},
+		{arg: "8080:", wantErr: true},
+		{arg: "70000:80", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := parsePortMapping(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePortMapping(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("parsePortMapping(%q) = %+v, want %+v", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target   string
+		wantKind string
+		wantErr  bool
+	}{
+		{target: "pods/web-0", wantKind: "pods"},
+		{target: "po/web-0", wantKind: "pods"},
+		{target: "svc/postgres", wantKind: "services"},
+		{target: "deployments/web", wantErr: true},
+		{target: "web-0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			kind, _, err := parseTarget(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTarget(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+			if kind != tt.wantKind {
+				t.Errorf("parseTarget(%q) kind = %s, want %s", tt.target, kind, tt.wantKind)
+			}
+		})
+	}
+}
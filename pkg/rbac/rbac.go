@@ -117,8 +117,12 @@ func match(list []string, val string) bool {
 // - restart: ONLY allows restart (independent, does NOT allow scale or edit)
 // - scale: ONLY allows scale (independent, does NOT allow restart or edit)
 // - edit: ONLY allows YAML editing (independent, does NOT allow restart or scale)
+// - trigger, suspend: ONLY allow running a CronJob now or suspending/resuming it
+// - debug: ONLY allows ephemeral debug containers, neither exec nor patch grant it
+// - portforward: ONLY allows forwarding to pod ports, exec does not grant it
+// - cp: ONLY allows copying files to and from containers, exec grants it as well
 //
-// Hierarchy: patch > {restart, scale, edit} (all three are siblings under patch)
+// Hierarchy: patch > {restart, scale, edit, trigger, suspend} (all siblings under patch)
 func matchVerb(list []string, verb string) bool {
 	// Check for explicit denial first (negation with !)
 	for _, v := range list {
@@ -142,7 +146,7 @@ func matchVerb(list []string, verb string) bool {
 	// If checking for 'restart', 'scale', or 'edit':
 	// - ONLY 'patch' permission can do them (as parent)
 	// - They are NOT inherited from each other
-	if verb == "restart" || verb == "scale" || verb == "edit" {
+	if verb == "restart" || verb == "scale" || verb == "edit" || verb == "trigger" || verb == "suspend" || verb == "pause" {
 		for _, v := range list {
 			if v == "patch" {
 				return true
@@ -150,6 +154,25 @@ func matchVerb(list []string, verb string) bool {
 		}
 	}
 	
+	// Copying files runs tar in the container, so 'exec' covers it
+	if verb == "cp" {
+		for _, v := range list {
+			if v == "exec" {
+				return true
+			}
+		}
+	}
+
+	// Roles written before 'watch' was enforced may opt in to 'get' granting live
+	// updates, unless 'watch' is explicitly denied above
+	if verb == "watch" && common.RBACGetGrantsWatch {
+		for _, v := range list {
+			if v == "get" {
+				return true
+			}
+		}
+	}
+
 	// Special case: if user has 'update' permission, they can also 'patch'
 	// and vice versa, for backward compatibility with existing RBAC configs
 	if verb == "patch" {
@@ -2,14 +2,17 @@ package rbac
 
 import (
 	"testing"
+
+	"github.com/xhilmi/kubedash/pkg/common"
 )
 
 func TestMatchVerb(t *testing.T) {
 	tests := []struct {
-		name     string
-		list     []string
-		verb     string
-		expected bool
+		name           string
+		list           []string
+		verb           string
+		getGrantsWatch bool
+		expected       bool
 	}{
 		// Direct matches
 		{
@@ -113,6 +116,126 @@ func TestMatchVerb(t *testing.T) {
 			expected: true,
 		},
 		
+		// Watch is only granted by get when RBAC_GET_GRANTS_WATCH is set
+		{
+			name:     "get cannot watch",
+			list:     []string{"get"},
+			verb:     "watch",
+			expected: false,
+		},
+		{
+			name:           "get can watch with opt-in",
+			list:           []string{"get"},
+			verb:           "watch",
+			getGrantsWatch: true,
+			expected:       true,
+		},
+		{
+			name:           "negation blocks watch with opt-in",
+			list:           []string{"!watch", "get"},
+			verb:           "watch",
+			getGrantsWatch: true,
+			expected:       false,
+		},
+		{
+			name:     "log cannot watch",
+			list:     []string{"log"},
+			verb:     "watch",
+			expected: false,
+		},
+		
+		// Pausing a rollout is granted by patch, not by restart
+		{
+			name:     "patch can pause",
+			list:     []string{"patch"},
+			verb:     "pause",
+			expected: true,
+		},
+		{
+			name:     "restart cannot pause",
+			list:     []string{"restart"},
+			verb:     "pause",
+			expected: false,
+		},
+		
+		// CronJob actions are granted by patch but stay independent otherwise
+		{
+			name:     "patch can trigger",
+			list:     []string{"patch"},
+			verb:     "trigger",
+			expected: true,
+		},
+		{
+			name:     "patch can suspend",
+			list:     []string{"patch"},
+			verb:     "suspend",
+			expected: true,
+		},
+		{
+			name:     "trigger cannot suspend",
+			list:     []string{"trigger"},
+			verb:     "suspend",
+			expected: false,
+		},
+		{
+			name:     "create cannot trigger",
+			list:     []string{"create"},
+			verb:     "trigger",
+			expected: false,
+		},
+		
+		// Debug containers are not granted by exec and do not grant exec
+		{
+			name:     "exec cannot debug",
+			list:     []string{"exec"},
+			verb:     "debug",
+			expected: false,
+		},
+		{
+			name:     "patch cannot debug",
+			list:     []string{"patch"},
+			verb:     "debug",
+			expected: false,
+		},
+		{
+			name:     "debug cannot exec",
+			list:     []string{"debug"},
+			verb:     "exec",
+			expected: false,
+		},
+		// Port forwarding is not granted by exec
+		{
+			name:     "exec cannot portforward",
+			list:     []string{"exec"},
+			verb:     "portforward",
+			expected: false,
+		},
+		{
+			name:     "portforward allows portforward",
+			list:     []string{"portforward"},
+			verb:     "portforward",
+			expected: true,
+		},
+		// File copy is granted by exec, but does not grant exec
+		{
+			name:     "exec can cp",
+			list:     []string{"exec"},
+			verb:     "cp",
+			expected: true,
+		},
+		{
+			name:     "cp cannot exec",
+			list:     []string{"cp"},
+			verb:     "exec",
+			expected: false,
+		},
+		{
+			name:     "negation blocks cp despite exec",
+			list:     []string{"!cp", "exec"},
+			verb:     "cp",
+			expected: false,
+		},
+		
 		// Negation tests
 		{
 			name:     "negation blocks restart",
@@ -144,6 +267,8 @@ func TestMatchVerb(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			common.RBACGetGrantsWatch = tt.getGrantsWatch
+			defer func() { common.RBACGetGrantsWatch = false }()
 			result := matchVerb(tt.list, tt.verb)
 			if result != tt.expected {
 				t.Errorf("matchVerb(%v, %q) = %v, expected %v", tt.list, tt.verb, result, tt.expected)
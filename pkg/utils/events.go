@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// EventTimes returns when an event was first and last seen. Events written through
+// events.k8s.io set eventTime and the series instead of the legacy timestamps.
+func EventTimes(e *corev1.Event) (first, last time.Time) {
+	first = e.FirstTimestamp.Time
+	if first.IsZero() {
+		first = e.EventTime.Time
+	}
+	if first.IsZero() {
+		first = e.CreationTimestamp.Time
+	}
+	last = e.LastTimestamp.Time
+	if e.Series != nil && e.Series.LastObservedTime.After(last) {
+		last = e.Series.LastObservedTime.Time
+	}
+	if e.EventTime.After(last) {
+		last = e.EventTime.Time
+	}
+	if last.Before(first) {
+		last = first
+	}
+	return first, last
+}
+
+// EventCount returns how often an event occurred, from the legacy count or the event series
+func EventCount(e *corev1.Event) int32 {
+	count := e.Count
+	if e.Series != nil && e.Series.Count > count {
+		count = e.Series.Count
+	}
+	return max(count, 1)
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEventTimesAndCount(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	created := now.Add(-time.Hour)
+	tests := []struct {
+		name      string
+		event     corev1.Event
+		wantFirst time.Time
+		wantLast  time.Time
+		wantCount int32
+	}{
+		{
+			name: "legacy event",
+			event: corev1.Event{
+				FirstTimestamp: metav1.NewTime(now.Add(-30 * time.Minute)),
+				LastTimestamp:  metav1.NewTime(now),
+				Count:          7,
+			},
+			wantFirst: now.Add(-30 * time.Minute),
+			wantLast:  now,
+			wantCount: 7,
+		},
+		{
+			name: "events.k8s.io event with series",
+			event: corev1.Event{
+				EventTime: metav1.NewMicroTime(now.Add(-10 * time.Minute)),
+				Series:    &corev1.EventSeries{Count: 3, LastObservedTime: metav1.NewMicroTime(now)},
+			},
+			wantFirst: now.Add(-10 * time.Minute),
+			wantLast:  now,
+			wantCount: 3,
+		},
+		{
+			name: "events.k8s.io event without series",
+			event: corev1.Event{
+				EventTime: metav1.NewMicroTime(now),
+			},
+			wantFirst: now,
+			wantLast:  now,
+			wantCount: 1,
+		},
+		{
+			name: "event without timestamps",
+			event: corev1.Event{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+			},
+			wantFirst: created,
+			wantLast:  created,
+			wantCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last := EventTimes(&tt.event)
+			if !first.Equal(tt.wantFirst) || !last.Equal(tt.wantLast) {
+				t.Errorf("EventTimes() = (%v, %v), want (%v, %v)", first, last, tt.wantFirst, tt.wantLast)
+			}
+			if count := EventCount(&tt.event); count != tt.wantCount {
+				t.Errorf("EventCount() = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
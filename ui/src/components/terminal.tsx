@@ -50,6 +50,9 @@ interface TerminalProps {
   pods?: Pod[]
   containers?: Container[]
   initContainers?: Container[]
+  // Start an ephemeral debug container targeting the selected container instead of exec
+  debug?: boolean
+  debugImage?: string
 }
 
 export function Terminal({
@@ -60,6 +63,8 @@ export function Terminal({
   containers: _containers = [],
   initContainers = [],
   type = 'pod',
+  debug = false,
+  debugImage,
 }: TerminalProps) {
   console.log('Terminal render', {
     namespace,
@@ -301,9 +306,12 @@ export function Terminal({
     // WebSocket connection
     setIsConnected(false)
     const currentCluster = localStorage.getItem('current-cluster')
+    const debugParams = debug
+      ? `&debug=true&target=${selectedContainer}${debugImage ? `&image=${encodeURIComponent(debugImage)}` : ''}`
+      : ''
     const wsPath =
       type === 'pod'
-        ? `/api/v1/terminal/${namespace}/${selectedPod}/ws?container=${selectedContainer}&x-cluster-name=${currentCluster}`
+        ? `/api/v1/terminal/${namespace}/${selectedPod}/ws?container=${selectedContainer}&x-cluster-name=${currentCluster}${debugParams}`
         : `/api/v1/node-terminal/${nodeName}/ws?x-cluster-name=${currentCluster}`
     const wsUrl = getWebSocketUrl(wsPath)
     const websocket = new WebSocket(wsUrl)
@@ -465,6 +473,8 @@ export function Terminal({
     selectedContainer,
     namespace,
     type,
+    debug,
+    debugImage,
     updateNetworkStats,
     reconnectFlag,
   ])
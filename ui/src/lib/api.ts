@@ -6,8 +6,10 @@ import { useQuery } from '@tanstack/react-query'
 import {
   APIKey,
   Cluster,
+  EventAggregate,
   FetchUserListResponse,
   ImageTagInfo,
+  NodeTerminalConfig,
   OAuthProvider,
   OverviewData,
   PodMetrics,
@@ -18,6 +20,7 @@ import {
   ResourceTypeMap,
   ResourceUsageHistory,
   Role,
+  TerminalRecording,
   UserItem,
 } from '@/types/api'
 
@@ -91,11 +94,13 @@ export interface SearchResult {
   namespace?: string
   resourceType: string
   createdAt: string
+  cluster?: string
 }
 
 export interface SearchResponse {
   results: SearchResult[]
   total: number
+  failedClusters?: string[]
 }
 
 // Global search API
@@ -104,6 +109,7 @@ export const globalSearch = async (
   options?: {
     limit?: number
     namespace?: string
+    allClusters?: boolean
   }
 ): Promise<SearchResponse> => {
   if (query.length < 2) {
@@ -118,6 +124,9 @@ export const globalSearch = async (
   if (options?.namespace) {
     params.append('namespace', options.namespace)
   }
+  if (options?.allClusters) {
+    params.append('allClusters', 'true')
+  }
 
   const endpoint = `/search?${params.toString()}`
   return fetchAPI<SearchResponse>(endpoint)
@@ -253,6 +262,24 @@ export const restartDeployment = async (
   await apiClient.post(`${endpoint}`)
 }
 
+// Restart statefulset - uses fine-grained RBAC endpoint
+export const restartStatefulSet = async (
+  name: string,
+  namespace: string
+): Promise<void> => {
+  const endpoint = `/statefulsets/${namespace}/${name}/restart`
+  await apiClient.post(`${endpoint}`)
+}
+
+// Restart daemonset - uses fine-grained RBAC endpoint
+export const restartDaemonSet = async (
+  name: string,
+  namespace: string
+): Promise<void> => {
+  const endpoint = `/daemonsets/${namespace}/${name}/restart`
+  await apiClient.post(`${endpoint}`)
+}
+
 // Edit deployment - uses fine-grained RBAC endpoint (edit verb only)
 export const editDeployment = async (
   name: string,
@@ -440,14 +467,53 @@ export interface ApplyResourceResponse {
   kind: string
   name: string
   namespace?: string
+  dryRun?: boolean
+  object?: Record<string, unknown>
+  diff?: string
+  results: ApplyResult[]
+}
+
+// Outcome of applying one object of a multi-document manifest
+export interface ApplyResult {
+  apiVersion: string
+  kind: string
+  name: string
+  namespace?: string
+  status: 'created' | 'configured' | 'unchanged' | 'failed'
+  dryRun?: boolean
+  error?: string
+  conflicts?: ApplyConflict[]
+  historyId?: number
+  diff?: string
+  object?: Record<string, unknown>
+}
+
+// Field owned by another manager that blocked a server-side apply
+export interface ApplyConflict {
+  manager: string
+  apiVersion?: string
+  field: string
+  message: string
 }
 
 export const applyResource = async (
-  yaml: string
+  yaml: string,
+  opts?: { dryRun?: boolean; force?: boolean }
 ): Promise<ApplyResourceResponse> => {
-  return await apiClient.post<ApplyResourceResponse>('/resources/apply', {
-    yaml,
-  })
+  const params = new URLSearchParams()
+  if (opts?.dryRun) {
+    params.append('dryRun', 'true')
+  }
+  if (opts?.force) {
+    params.append('force', 'true')
+  }
+  const query = params.toString()
+  return await apiClient.post<ApplyResourceResponse>(
+    `/resources/apply${query ? `?${query}` : ''}`,
+    {
+      yaml,
+    }
+  )
 }
 
 export const useResourcesEvents = <T extends ResourceType>(
@@ -511,6 +577,128 @@ export const useResources = <T extends ResourceType>(
   })
 }
 
+export interface ResourceTableColumn {
+  name: string
+  type: string
+  format: string
+  description: string
+  priority: number
+}
+
+export interface ResourceTableRow {
+  cells: unknown[]
+  object: {
+    metadata: {
+      name: string
+      namespace?: string
+      uid?: string
+      creationTimestamp?: string
+      labels?: Record<string, string>
+    }
+  }
+}
+
+// Server-side printed table: kubectl get columns for built-in kinds,
+// additionalPrinterColumns for custom resources
+export interface ResourceTable {
+  kind: 'Table'
+  apiVersion: string
+  metadata: { resourceVersion?: string; continue?: string }
+  columnDefinitions: ResourceTableColumn[]
+  rows: ResourceTableRow[]
+}
+
+export type TopologyHealth =
+  | 'healthy'
+  | 'progressing'
+  | 'degraded'
+  | 'missing'
+  | 'unknown'
+
+export interface TopologyNode {
+  id: string
+  type: string
+  apiVersion?: string
+  name: string
+  namespace?: string
+  health: TopologyHealth
+  status?: string
+}
+
+export interface TopologyEdge {
+  source: string
+  target: string
+  type:
+    | 'owns'
+    | 'selects'
+    | 'routes'
+    | 'parent'
+    | 'mounts'
+    | 'binds'
+    | 'storageclass'
+    | 'scales'
+}
+
+export interface NamespaceTopology {
+  namespace: string
+  nodes: TopologyNode[]
+  edges: TopologyEdge[]
+}
+
+export const useNamespaceTopology = (
+  namespace: string,
+  options?: { refreshInterval?: number; enabled?: boolean }
+) => {
+  return useQuery({
+    queryKey: ['topology', namespace],
+    queryFn: () => fetchAPI<NamespaceTopology>(`/topology/${namespace}`),
+    enabled: options?.enabled ?? !!namespace,
+    placeholderData: (prevData) => prevData,
+    refetchInterval: options?.refreshInterval || 0,
+  })
+}
+
+export const fetchResourceTable = (
+  resource: string,
+  namespace?: string,
+  options?: { wide?: boolean; labelSelector?: string; fieldSelector?: string }
+): Promise<ResourceTable> => {
+  const params = new URLSearchParams({ as: 'table' })
+  if (options?.wide) params.append('wide', 'true')
+  if (options?.labelSelector)
+    params.append('labelSelector', options.labelSelector)
+  if (options?.fieldSelector)
+    params.append('fieldSelector', options.fieldSelector)
+  return fetchAPI<ResourceTable>(
+    `/${resource}/${namespace || '_all'}?${params.toString()}`
+  )
+}
+
+export const useResourceTable = (
+  resource: string,
+  namespace?: string,
+  options?: {
+    wide?: boolean
+    labelSelector?: string
+    fieldSelector?: string
+    refreshInterval?: number
+  }
+) => {
+  return useQuery({
+    queryKey: [
+      'resource-table',
+      resource,
+      namespace,
+      options?.wide,
+      options?.labelSelector,
+      options?.fieldSelector,
+    ],
+    queryFn: () => fetchResourceTable(resource, namespace, options),
+    placeholderData: (prevData) => prevData,
+    refetchInterval: options?.refreshInterval || 0,
+  })
+}
+
 // Hook: SSE watch for resource lists (initial snapshot + ADDED/MODIFIED/DELETED)
 export function useResourcesWatch<T extends ResourceType>(
   resource: T,
@@ -654,6 +842,77 @@ export function useResourcesWatch<T extends ResourceType>(
   return { data, isLoading, error, isConnected, refetch, stop: disconnect }
 }
 
+// Hook: SSE stream of events aggregated by involved object and reason
+export function useEventStream(
+  namespace?: string,
+  options?: {
+    type?: 'Warning' | 'Normal'
+    reason?: string
+    kind?: string
+    enabled?: boolean
+  }
+) {
+  const [data, setData] = useState<EventAggregate[] | undefined>(undefined)
+  const [error, setError] = useState<Error | null>(null)
+  const [isConnected, setIsConnected] = useState(false)
+
+  useEffect(() => {
+    setData(undefined)
+    if (options?.enabled === false) return
+    const params = new URLSearchParams()
+    if (options?.type) params.append('type', options.type)
+    if (options?.reason) params.append('reason', options.reason)
+    if (options?.kind) params.append('kind', options.kind)
+    const cluster = localStorage.getItem('current-cluster')
+    if (cluster) params.append('x-cluster-name', cluster)
+    const url = withSubPath(
+      `${API_BASE_URL}/events/${namespace || '_all'}/stream?${params.toString()}`
+    )
+
+    setError(null)
+    const es = new EventSource(url, { withCredentials: true })
+    es.onopen = () => setIsConnected(true)
+    es.onerror = () => setIsConnected(false)
+
+    // Keep the most recently seen aggregates first
+    const upsert = (e: MessageEvent<string>) => {
+      const aggregate = JSON.parse(e.data) as EventAggregate
+      setData((prev) => [
+        aggregate,
+        ...(prev || []).filter((it) => it.key !== aggregate.key),
+      ])
+    }
+    es.addEventListener('snapshot', (e: MessageEvent<string>) => {
+      setData(JSON.parse(e.data) as EventAggregate[])
+    })
+    es.addEventListener('added', upsert)
+    es.addEventListener('modified', upsert)
+    es.addEventListener('deleted', (e: MessageEvent<string>) => {
+      const aggregate = JSON.parse(e.data) as EventAggregate
+      setData((prev) => prev?.filter((it) => it.key !== aggregate.key))
+    })
+    es.addEventListener('error', (e: MessageEvent) => {
+      try {
+        const payload = JSON.parse(e.data)
+        setError(new Error(payload?.error || 'SSE error'))
+      } catch {
+        setError(new Error('SSE error'))
+      }
+    })
+    es.addEventListener('close', () => setIsConnected(false))
+
+    return () => es.close()
+  }, [
+    namespace,
+    options?.type,
+    options?.reason,
+    options?.kind,
+    options?.enabled,
+  ])
+
+  return { data, error, isConnected }
+}
+
 export const fetchResource = <T>(
   resource: string,
   name: string,
@@ -954,6 +1213,104 @@ export const createLogsSSEStream = (
   return eventSource
 }
 
+// Function to build the download URL of pod logs, podName '_all' with a
+// labelSelector downloads a tar archive with one file per pod and container
+export const getPodLogsDownloadUrl = (
+  namespace: string,
+  podName: string,
+  options?: {
+    container?: string
+    previous?: boolean
+    timestamps?: boolean
+    tailLines?: number
+    sinceSeconds?: number
+    sinceTime?: string
+    untilTime?: string
+    labelSelector?: string
+    gzip?: boolean
+  }
+): string => {
+  const params = new URLSearchParams()
+  if (options?.container) params.append('container', options.container)
+  if (options?.previous) params.append('previous', 'true')
+  if (options?.timestamps) params.append('timestamps', 'true')
+  if (options?.tailLines !== undefined)
+    params.append('tailLines', options.tailLines.toString())
+  if (options?.sinceSeconds !== undefined)
+    params.append('sinceSeconds', options.sinceSeconds.toString())
+  if (options?.sinceTime) params.append('sinceTime', options.sinceTime)
+  if (options?.untilTime) params.append('untilTime', options.untilTime)
+  if (options?.labelSelector)
+    params.append('labelSelector', options.labelSelector)
+  if (options?.gzip) params.append('gzip', 'true')
+  const cluster = localStorage.getItem('current-cluster')
+  if (cluster) params.append('x-cluster-name', cluster)
+  return withSubPath(
+    `${API_BASE_URL}/logs/${namespace}/${podName}/download?${params.toString()}`
+  )
+}
+
+// Function to build the download URL of a file or directory in a container,
+// directories are downloaded as a tar archive
+export const getPodFileDownloadUrl = (
+  namespace: string,
+  podName: string,
+  path: string,
+  container?: string
+): string => {
+  const params = new URLSearchParams({ path })
+  if (container) params.append('container', container)
+  const cluster = localStorage.getItem('current-cluster')
+  if (cluster) params.append('x-cluster-name', cluster)
+  return withSubPath(
+    `${API_BASE_URL}/cp/${namespace}/${podName}/download?${params.toString()}`
+  )
+}
+
+// Upload files into a directory of a container
+export const uploadPodFiles = async (
+  namespace: string,
+  podName: string,
+  path: string,
+  files: File[],
+  container?: string
+): Promise<{ path: string; files: string[]; bytes: number }> => {
+  const params = new URLSearchParams({ path })
+  if (container) params.append('container', container)
+  const form = new FormData()
+  files.forEach((file) => form.append('file', file))
+  const headers: Record<string, string> = {}
+  const cluster = localStorage.getItem('current-cluster')
+  if (cluster) headers['x-cluster-name'] = cluster
+  const response = await fetch(
+    withSubPath(
+      `${API_BASE_URL}/cp/${namespace}/${podName}/upload?${params.toString()}`
+    ),
+    { method: 'POST', credentials: 'include', headers, body: form }
+  )
+  if (!response.ok) {
+    const errorData = await response.json().catch(() => ({}))
+    throw new Error(errorData.error || `HTTP error! status: ${response.status}`)
+  }
+  return response.json()
+}
+
+// Function to build the WebSocket URL forwarding one TCP connection to a pod or
+// service port, binary frames carry the data and text frames JSON status messages
+export const getPortForwardWebSocketUrl = (
+  namespace: string,
+  kind: 'pods' | 'services',
+  name: string,
+  port: number | string
+): string => {
+  const params = new URLSearchParams({ port: port.toString() })
+  const cluster = localStorage.getItem('current-cluster')
+  if (cluster) params.append('x-cluster-name', cluster)
+  return getWebSocketUrl(
+    `/api/v1/portforward/${namespace}/${kind}/${name}/ws?${params.toString()}`
+  )
+}
+
 // Hook for streaming logs with SSE and real-time updates
 export const useLogsStream = (
   namespace: string,
@@ -1254,6 +1611,16 @@ export const importClusters = async (
   await apiClient.post('/admin/clusters/import', request)
 }
 
+// Server-side log filter, fields are JSON log conditions like 'level>=warn'
+export interface LogFilterOptions {
+  include?: string
+  exclude?: string
+  caseSensitive?: boolean
+  before?: number
+  after?: number
+  fields?: string[]
+}
+
 export const useLogsWebSocket = (
   namespace: string,
   podName: string,
@@ -1265,6 +1632,8 @@ export const useLogsWebSocket = (
     sinceSeconds?: number
     enabled?: boolean
     labelSelector?: string
+    // Workload reference like 'deployments/foo', container '*' tails every container
+    workload?: string
     onNewLog?: (log: string) => void
     onClear?: () => void
   }
@@ -1293,6 +1662,9 @@ export const useLogsWebSocket = (
     if (options.labelSelector) {
       params.append('labelSelector', options.labelSelector)
     }
+    if (options.workload) {
+      params.append('workload', options.workload)
+    }
 
     const currentCluster = localStorage.getItem('current-cluster')
     if (currentCluster) {
@@ -1311,6 +1683,7 @@ export const useLogsWebSocket = (
     options?.sinceSeconds,
     options?.enabled,
     options?.labelSelector,
+    options?.workload,
   ])
 
   // WebSocket event handlers
@@ -1328,6 +1701,9 @@ export const useLogsWebSocket = (
         case 'close':
           console.log('Log stream closed:', message.data)
           break
+        case 'filter':
+          console.debug('Log filter applied')
+          break
       }
     },
     [options]
@@ -1383,6 +1759,14 @@ export const useLogsWebSocket = (
     }
   }, [options])
 
+  // Replace the server-side filter without reconnecting, undefined clears it
+  const setFilter = useCallback(
+    (filter?: LogFilterOptions) => {
+      return wsActions.send({ type: 'filter', filter: filter ?? {} })
+    },
+    [wsActions]
+  )
+
   return useMemo(
     () => ({
       isLoading: wsState.isConnecting,
@@ -1392,6 +1776,7 @@ export const useLogsWebSocket = (
       refetch,
       stopStreaming,
       clearLogs,
+      setFilter,
     }),
     [
       wsState.isConnecting,
@@ -1401,6 +1786,7 @@ export const useLogsWebSocket = (
       refetch,
       stopStreaming,
       clearLogs,
+      setFilter,
     ]
   )
 }
@@ -1412,6 +1798,9 @@ export interface ClusterCreateRequest {
   prometheusURL?: string
   inCluster?: boolean
   isDefault?: boolean
+  recordTerminal?: boolean
+  archiveEvents?: boolean
+  nodeTerminalConfig?: NodeTerminalConfig
 }
 
 export interface ClusterUpdateRequest extends ClusterCreateRequest {
@@ -1619,6 +2008,70 @@ export const useUserList = (page = 1, size = 20) => {
   })
 }
 
+// Terminal recording API (admin)
+export interface TerminalRecordingListResponse {
+  recordings: TerminalRecording[]
+  total: number
+  page: number
+  size: number
+}
+
+export interface TerminalRecordingReplay {
+  recording: TerminalRecording
+  header: {
+    version: number
+    width: number
+    height: number
+    timestamp: number
+    title?: string
+  }
+  // [seconds since start, 'o' | 'i' | 'r', data]
+  events: [number, string, string][]
+  // Cursor of the next page of events, null once the recording is read to the end
+  next: number | null
+}
+
+export const fetchTerminalRecordings = async (
+  filter: {
+    cluster?: string
+    kind?: 'pod' | 'node'
+    user?: string
+    namespace?: string
+    pod?: string
+    node?: string
+  } = {},
+  page = 1,
+  size = 20
+): Promise<TerminalRecordingListResponse> => {
+  const params = new URLSearchParams({ page: String(page), size: String(size) })
+  Object.entries(filter).forEach(([key, value]) => {
+    if (value) params.append(key, value)
+  })
+  return fetchAPI<TerminalRecordingListResponse>(
+    `/admin/terminal-recordings/?${params.toString()}`
+  )
+}
+
+export const fetchTerminalRecordingReplay = async (
+  id: number,
+  cursor?: number,
+  limit?: number
+): Promise<TerminalRecordingReplay> => {
+  const params = new URLSearchParams()
+  if (cursor) params.append('cursor', String(cursor))
+  if (limit) params.append('limit', String(limit))
+  const query = params.toString()
+  return fetchAPI<TerminalRecordingReplay>(
+    `/admin/terminal-recordings/${id}/replay${query ? `?${query}` : ''}`
+  )
+}
+
+export const getTerminalRecordingDownloadUrl = (id: number): string => {
+  return withSubPath(
+    `${API_BASE_URL}/admin/terminal-recordings/${id}/download`
+  )
+}
+
 // Resource History API
 export const fetchResourceHistory = (
   resourceType: string,
@@ -13,7 +13,12 @@ import { Container } from 'kubernetes-types/core/v1'
 import { useTranslation } from 'react-i18next'
 import { toast } from 'sonner'
 
-import { updateResource, useResource, useResourcesWatch } from '@/lib/api'
+import {
+  restartDaemonSet,
+  updateResource,
+  useResource,
+  useResourcesWatch,
+} from '@/lib/api'
 import { formatDate, translateError } from '@/lib/utils'
 import { Badge } from '@/components/ui/badge'
 import { Button } from '@/components/ui/button'
@@ -129,22 +134,7 @@ export function DaemonSetDetail(props: { namespace: string; name: string }) {
     if (!daemonset) return
 
     try {
-      // Create a deep copy of the daemonset to avoid modifying the original
-      const updatedDaemonSet = {
-        ...daemonset,
-      }
-
-      // Ensure annotations object exists
-      if (!updatedDaemonSet.spec!.template!.metadata!.annotations) {
-        updatedDaemonSet.spec!.template!.metadata!.annotations = {}
-      }
-
-      // Add restart annotation to trigger pod restart
-      updatedDaemonSet.spec!.template!.metadata!.annotations[
-        'kite.kubernetes.io/restartedAt'
-      ] = new Date().toISOString()
-
-      await updateResource('daemonsets', name, namespace, updatedDaemonSet)
+      await restartDaemonSet(name, namespace)
       toast.success('DaemonSet restart initiated')
       setIsRestartPopoverOpen(false)
       setRefreshInterval(1000)
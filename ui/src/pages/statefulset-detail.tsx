@@ -14,7 +14,12 @@ import { Container } from 'kubernetes-types/core/v1'
 import { useTranslation } from 'react-i18next'
 import { toast } from 'sonner'
 
-import { updateResource, useResource, useResourcesWatch } from '@/lib/api'
+import {
+  restartStatefulSet,
+  updateResource,
+  useResource,
+  useResourcesWatch,
+} from '@/lib/api'
 import { formatDate, translateError } from '@/lib/utils'
 import { Badge } from '@/components/ui/badge'
 import { Button } from '@/components/ui/button'
@@ -164,30 +169,7 @@ export function StatefulSetDetail(props: { namespace: string; name: string }) {
     if (!statefulset) return
 
     try {
-      const updatedStatefulSet = { ...statefulset } as StatefulSet
-      if (!updatedStatefulSet.spec) {
-        updatedStatefulSet.spec = {
-          selector: { matchLabels: {} },
-          template: { spec: { containers: [] } },
-          serviceName: '',
-        }
-      }
-      if (!updatedStatefulSet.spec.template) {
-        updatedStatefulSet.spec.template = { spec: { containers: [] } }
-      }
-      if (!updatedStatefulSet.spec.template.metadata) {
-        updatedStatefulSet.spec.template.metadata = {}
-      }
-      if (!updatedStatefulSet.spec.template.metadata.annotations) {
-        updatedStatefulSet.spec.template.metadata.annotations = {}
-      }
-
-      // Add restart annotation to trigger pod restart
-      updatedStatefulSet.spec.template.metadata.annotations[
-        'kite.kubernetes.io/restartedAt'
-      ] = new Date().toISOString()
-
-      await updateResource('statefulsets', name, namespace, updatedStatefulSet)
+      await restartStatefulSet(name, namespace)
       toast.success('StatefulSet restart initiated')
       setIsRestartPopoverOpen(false)
       setRefreshInterval(1000)
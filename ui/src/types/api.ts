@@ -338,6 +338,58 @@ export interface Cluster {
   createdAt: string
   updatedAt: string
   prometheusURL?: string
+  recordTerminal?: boolean
+  archiveEvents?: boolean
+  nodeTerminalConfig?: NodeTerminalConfig
+}
+
+export interface NodeTerminalConfig {
+  namespace?: string
+  image?: string
+  cpuRequest?: string
+  memoryRequest?: string
+  cpuLimit?: string
+  memoryLimit?: string
+  imagePullSecrets?: string[]
+  nodeSelector?: Record<string, string>
+  idleTimeout?: string
+}
+
+// Events of one involved object and reason, aggregated by the event stream
+export interface EventAggregate {
+  key: string
+  namespace: string
+  involvedObject: {
+    kind: string
+    name: string
+    namespace?: string
+    apiVersion?: string
+    uid?: string
+  }
+  reason: string
+  type: 'Warning' | 'Normal'
+  message: string
+  source?: string
+  count: number
+  firstSeen: string
+  lastSeen: string
+}
+
+export interface TerminalRecording {
+  id: number
+  clusterName: string
+  kind: 'pod' | 'node'
+  namespace: string
+  podName: string
+  container: string
+  nodeName?: string
+  operatorId: number
+  operatorName: string
+  startedAt: string
+  endedAt?: string
+  size: number
+  createdAt: string
+  updatedAt: string
 }
 
 export interface OAuthProvider {